@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+
+	// everyone grants a role to all authenticated users.
+	everyone = "*"
+)
+
+var (
+	ErrForbidden   = errors.New("access denied")
+	ErrInvalidRole = errors.New("role must be viewer, editor or owner")
+)
+
+// roleRanks orders the roles, every role includes the ones below it.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// AccessList shows who may access a document. Folder grants apply to all
+// documents and come from the configuration.
+type AccessList struct {
+	weaver.AutoMarshal
+	Grants       map[string]string `json:"grants"`
+	FolderGrants map[string]string `json:"folderGrants"`
+}
+
+// aDocConfig configures the ADocRepository component.
+type aDocConfig struct {
+	// FolderRoles grants roles on all documents, e.g. {"*" = "viewer",
+	// "alice" = "owner"}. Without any, every user owns every document.
+	FolderRoles map[string]string `toml:"folder_roles"`
+}
+
+func (a *aDocRepository) Init(context.Context) error {
+	for user, role := range a.Config().FolderRoles {
+		if roleRanks[role] == 0 {
+			return fmt.Errorf("folder role of %s: %w", user, ErrInvalidRole)
+		}
+	}
+	return nil
+}
+
+func (a *aDocRepository) folderRoles() map[string]string {
+	if len(a.Config().FolderRoles) == 0 {
+		return map[string]string{everyone: RoleOwner}
+	}
+	return a.Config().FolderRoles
+}
+
+// GetRole returns the role of a user on a document, the higher one of the
+// folder and the document grants, or "" if the user has no access. An empty
+// fileName returns the folder role. The anonymous user of a server without
+// authentication owns everything. Every request about a document passes here
+// first, so invalid names are rejected before anything else.
+func (a *aDocRepository) GetRole(ctx context.Context, fileName string, user string) (string, error) {
+	var name DocumentName
+	if fileName != "" {
+		var err error
+		if name, err = parseDocumentName(fileName); err != nil {
+			return "", err
+		}
+	}
+
+	if user == "" {
+		return RoleOwner, nil
+	}
+
+	role := higherRole(a.folderRoles()[everyone], a.folderRoles()[user])
+	if name == "" {
+		return role, nil
+	}
+
+	grants, err := a.readGrants(name)
+	if err != nil {
+		return "", err
+	}
+	return higherRole(role, higherRole(grants[everyone], grants[user])), nil
+}
+
+// Authorize returns ErrForbidden unless the user has at least the role.
+func (a *aDocRepository) Authorize(ctx context.Context, fileName string, user string, role string) error {
+	actual, err := a.GetRole(ctx, fileName, user)
+	if err != nil {
+		return err
+	}
+	if roleRanks[actual] < roleRanks[role] && fileName == "" {
+		return fmt.Errorf("%w: %s needs to be %s of all documents", ErrForbidden, user, role)
+	} else if roleRanks[actual] < roleRanks[role] {
+		return fmt.Errorf("%w: %s needs to be %s of %q", ErrForbidden, user, role, fileName)
+	}
+	return nil
+}
+
+// GetVisibleFiles is GetFiles limited to the documents the user may view.
+func (a *aDocRepository) GetVisibleFiles(ctx context.Context, user string) ([]string, error) {
+	files, err := a.GetFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var visible []string
+	for _, file := range files {
+		role, err := a.GetRole(ctx, file, user)
+		if err != nil {
+			return nil, err
+		}
+		if role != "" {
+			visible = append(visible, file)
+		}
+	}
+	return visible, nil
+}
+
+func (a *aDocRepository) GetAccess(ctx context.Context, fileName string) (AccessList, error) {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return AccessList{}, err
+	}
+	if _, err := os.Stat(name.originalPath()); err != nil {
+		return AccessList{}, err
+	}
+
+	grants, err := a.readGrants(name)
+	if err != nil {
+		return AccessList{}, err
+	}
+	return AccessList{Grants: grants, FolderGrants: a.folderRoles()}, nil
+}
+
+// Share grants a role on a document to a user, or to everyone with "*". An
+// empty role revokes the grant.
+func (a *aDocRepository) Share(ctx context.Context, fileName string, user string, role string) (AccessList, error) {
+	if role != "" && roleRanks[role] == 0 {
+		return AccessList{}, ErrInvalidRole
+	}
+	if user == "" {
+		return AccessList{}, fmt.Errorf("no user to share with")
+	}
+	if err := a.ensureWorkDirExists(); err != nil {
+		return AccessList{}, err
+	}
+
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return AccessList{}, err
+	}
+	access, err := a.GetAccess(ctx, fileName)
+	if err != nil {
+		return AccessList{}, err
+	}
+	if role == "" {
+		delete(access.Grants, user)
+	} else {
+		access.Grants[user] = role
+	}
+
+	data, err := json.MarshalIndent(access.Grants, "", "  ")
+	if err != nil {
+		return AccessList{}, err
+	}
+	return access, ioutil.WriteFile(name.workPath(".acl.json"), data, 0644)
+}
+
+func (a *aDocRepository) readGrants(name DocumentName) (map[string]string, error) {
+	grants := make(map[string]string)
+	data, err := ioutil.ReadFile(name.workPath(".acl.json"))
+	if os.IsNotExist(err) {
+		return grants, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return grants, json.Unmarshal(data, &grants)
+}
+
+func higherRole(a string, b string) string {
+	if roleRanks[b] > roleRanks[a] {
+		return b
+	}
+	return a
+}
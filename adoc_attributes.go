@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Attribute is a single `:name: value` entry of an AsciiDoc document header.
+type Attribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+var (
+	attributeNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_-]*$`)
+	attributeLinePattern = regexp.MustCompile(`^:([A-Za-z0-9_][A-Za-z0-9_-]*):\s*(.*)$`)
+)
+
+// headerEnd returns the number of leading lines that make up the document
+// header, i.e. everything up to the first blank line. Documents that neither
+// start with a title nor an attribute entry have no header.
+func headerEnd(lines []string) int {
+	if len(lines) == 0 || !(strings.HasPrefix(lines[0], "= ") || attributeLinePattern.MatchString(strings.TrimRight(lines[0], "\r"))) {
+		return 0
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+func parseHeaderAttributes(markup []byte) []Attribute {
+	lines := strings.Split(string(markup), "\n")
+
+	attributes := []Attribute{}
+	for _, line := range lines[:headerEnd(lines)] {
+		match := attributeLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+		attributes = append(attributes, Attribute{Name: match[1], Value: match[2]})
+	}
+	return attributes
+}
+
+// setHeaderAttributes replaces the values of existing header attributes and
+// appends the ones that are not yet present to the end of the header.
+func setHeaderAttributes(markup []byte, values map[string]string) []byte {
+	lines := strings.Split(string(markup), "\n")
+	end := headerEnd(lines)
+
+	seen := make(map[string]bool)
+	for i := 0; i < end; i++ {
+		match := attributeLinePattern.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+		if match == nil {
+			continue
+		}
+		if value, ok := values[match[1]]; ok {
+			lines[i] = formatAttribute(match[1], value)
+			seen[match[1]] = true
+		}
+	}
+
+	var added []string
+	for _, name := range sortedKeys(values) {
+		if !seen[name] {
+			added = append(added, formatAttribute(name, values[name]))
+		}
+	}
+	if len(added) == 0 {
+		return []byte(strings.Join(lines, "\n"))
+	}
+
+	result := make([]string, 0, len(lines)+len(added)+1)
+	result = append(result, lines[:end]...)
+	result = append(result, added...)
+	if end == 0 {
+		// A new header needs a blank line to separate it from the content
+		result = append(result, "")
+	}
+	result = append(result, lines[end:]...)
+	return []byte(strings.Join(result, "\n"))
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatAttribute(name string, value string) string {
+	value = strings.TrimSpace(strings.ReplaceAll(value, "\n", " "))
+	if value == "" {
+		return ":" + name + ":"
+	}
+	return ":" + name + ": " + value
+}
+
+// replaceTableRows swaps the body rows of the first table that declares a
+// header row (`[options="header"]`) for the given rows.
+func replaceTableRows(markup []byte, rows [][]string) []byte {
+	lines := strings.Split(string(markup), "\n")
+
+	start := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "|===" && strings.Contains(lines[i-1], `options="header"`) {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return markup
+	}
+
+	header := -1
+	end := -1
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "|===" {
+			end = i
+			break
+		}
+		if header == -1 && trimmed != "" {
+			header = i
+		}
+	}
+	if header == -1 || end == -1 {
+		return markup
+	}
+
+	body := []string{""}
+	for _, row := range rows {
+		body = append(body, formatTableRow(row))
+	}
+	body = append(body, "")
+
+	var result bytes.Buffer
+	result.WriteString(strings.Join(lines[:header+1], "\n"))
+	result.WriteString("\n")
+	result.WriteString(strings.Join(body, "\n"))
+	result.WriteString("\n")
+	result.WriteString(strings.Join(lines[end:], "\n"))
+	return result.Bytes()
+}
+
+func formatTableRow(cells []string) string {
+	formatted := make([]string, len(cells))
+	for i, cell := range cells {
+		formatted[i] = "|" + strings.TrimSpace(strings.ReplaceAll(cell, "|", `\|`))
+	}
+	return strings.Join(formatted, " ")
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ServiceWeaver/weaver"
@@ -18,14 +24,99 @@ const (
 	adocsDirName = "adocs"
 )
 
+const (
+	StatusDraft    = "draft"
+	StatusFinal    = "final"
+	StatusArchived = "archived"
+)
+
+var (
+	// ErrDocumentLocked is returned when a final or archived document is edited.
+	ErrDocumentLocked = errors.New("document is finalized and can no longer be changed")
+	ErrNothingToUndo  = errors.New("document has no changes to undo")
+	ErrNoSuchVersion  = errors.New("document has no such version")
+	// ErrInvalidTransition is returned when a document cannot move to the
+	// requested state, e.g. when a draft is archived.
+	ErrInvalidTransition = errors.New("document cannot change to that state")
+	// ErrDocumentChanged is returned when a document was changed while it
+	// was being finalized.
+	ErrDocumentChanged = errors.New("document was changed in the meantime")
+)
+
 type ADocRepository interface {
 	GetFiles(context.Context) ([]string, error)
 	ReadFile(context.Context, string) ([]byte, error)
-	SaveVariantForFile(context.Context, string, []byte) error
+	SaveVariantForFile(context.Context, string, []byte, string, string) error
+	GetState(context.Context, string) (DocumentState, error)
+	Finalize(context.Context, string, []byte, []byte) (DocumentState, error)
+	Archive(context.Context, string) (DocumentState, error)
+	ReadFinalPDF(context.Context, string) ([]byte, error)
+	ReadVersion(context.Context, string, int) ([]byte, error)
+	ListVersions(context.Context, string) ([]DocumentVersion, error)
+	Undo(context.Context, string) error
+	GetRole(context.Context, string, string) (string, error)
+	Authorize(context.Context, string, string, string) error
+	GetVisibleFiles(context.Context, string) ([]string, error)
+	GetAccess(context.Context, string) (AccessList, error)
+	Share(context.Context, string, string, string) (AccessList, error)
+}
+
+// DocumentState is the lifecycle state of a document. Documents start as
+// drafts, become final once sent and are archived afterwards.
+type DocumentState struct {
+	weaver.AutoMarshal
+	Status      string    `json:"status"`
+	FinalizedAt time.Time `json:"finalizedAt,omitempty"`
+	PDFHash     string    `json:"pdfHash,omitempty"`
+}
+
+func (s DocumentState) Locked() bool {
+	return s.Status == StatusFinal || s.Status == StatusArchived
+}
+
+// DocumentVersion is one saved version of a document, numbered like the
+// versions of ReadVersion.
+type DocumentVersion struct {
+	weaver.AutoMarshal
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Author    string    `json:"author,omitempty"`
+	Original  bool      `json:"original,omitempty"`
+	// VoicePromptID is the archived clip the change was dictated with.
+	VoicePromptID string `json:"voicePromptId,omitempty"`
+}
+
+// variantMetadata is stored next to a variant as <variant>.json.
+type variantMetadata struct {
+	Author        string `json:"author"`
+	VoicePromptID string `json:"voicePromptId,omitempty"`
 }
 
 type aDocRepository struct {
 	weaver.Implements[ADocRepository]
+	weaver.WithConfig[aDocConfig]
+
+	mu    sync.Mutex
+	locks map[DocumentName]*sync.Mutex
+}
+
+// lock serializes the changes of a document, so no variant is saved between
+// checking the state and writing, or while the document is finalized. It
+// returns the unlock function.
+func (a *aDocRepository) lock(name DocumentName) func() {
+	a.mu.Lock()
+	if a.locks == nil {
+		a.locks = make(map[DocumentName]*sync.Mutex)
+	}
+	l, ok := a.locks[name]
+	if !ok {
+		l = &sync.Mutex{}
+		a.locks[name] = l
+	}
+	a.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
 }
 
 func (a *aDocRepository) GetFiles(ctx context.Context) ([]string, error) {
@@ -37,12 +128,16 @@ func (a *aDocRepository) GetFiles(ctx context.Context) ([]string, error) {
 	return a.filterAdocFiles(files), nil
 }
 
+// filterAdocFiles skips files whose names could not be requested.
 func (a *aDocRepository) filterAdocFiles(files []os.FileInfo) []string {
 	var fileNames []string
 	for _, file := range files {
 		if !file.IsDir() && strings.HasSuffix(file.Name(), ".adoc") {
-			fileName := strings.TrimSuffix(file.Name(), ".adoc")
-			fileNames = append(fileNames, fileName)
+			name, err := parseDocumentName(file.Name())
+			if err != nil || string(name)+".adoc" != file.Name() {
+				continue
+			}
+			fileNames = append(fileNames, string(name))
 		}
 	}
 	return fileNames
@@ -53,7 +148,7 @@ type FileVariant struct {
 	Date     time.Time
 }
 
-func (a *aDocRepository) GetVariantionsForFile(ctx context.Context, fileName string) ([]FileVariant, error) {
+func (a *aDocRepository) GetVariantionsForFile(ctx context.Context, name DocumentName) ([]FileVariant, error) {
 	if err := a.ensureWorkDirExists(); err != nil {
 		return nil, err
 	}
@@ -63,67 +158,182 @@ func (a *aDocRepository) GetVariantionsForFile(ctx context.Context, fileName str
 		return nil, err
 	}
 
-	return a.filterFileVariants(files, fileName), nil
+	return a.filterFileVariants(files, name), nil
 }
 
-func (a *aDocRepository) filterFileVariants(files []os.FileInfo, fileName string) []FileVariant {
+func (a *aDocRepository) filterFileVariants(files []os.FileInfo, name DocumentName) []FileVariant {
 	var variants []FileVariant
 	for _, file := range files {
-		if !file.IsDir() {
-			fileNameWithTimestamp := file.Name()
-			if strings.HasPrefix(fileNameWithTimestamp, fileName) && strings.HasSuffix(fileNameWithTimestamp, ".adoc") {
-				variation := strings.TrimSuffix(strings.TrimPrefix(fileNameWithTimestamp, fileName), ".adoc")
-
-				timestamp := strings.TrimPrefix(variation, "_")
-				t, err := time.Parse("20060102_150405", timestamp)
-				if err != nil {
-					continue // skip the variant if timestamp is not valid
-				}
-
-				fileVariant := FileVariant{
-					FileName: workDirName + "/" + fileNameWithTimestamp,
-					Date:     t,
-				}
-				variants = append(variants, fileVariant)
-			}
+		if file.IsDir() {
+			continue
+		}
+		t, ok := name.variantTime(file.Name())
+		if !ok {
+			continue // skip other documents and files that are no variants
+		}
+
+		fileVariant := FileVariant{
+			FileName: filepath.Join(workDirName, file.Name()),
+			Date:     t,
 		}
+		variants = append(variants, fileVariant)
 	}
 	return variants
 }
 
 func (a *aDocRepository) ReadFile(ctx context.Context, fileName string) ([]byte, error) {
-	filePath := adocsDirName + "/" + fileName + ".adoc"
+	return a.ReadVersion(ctx, fileName, 0)
+}
+
+// ReadVersion returns an older version of a document. Version 0 is the
+// newest one, 1 the one before and so on.
+func (a *aDocRepository) ReadVersion(ctx context.Context, fileName string, version int) ([]byte, error) {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return nil, err
+	}
 
-	variants, err := a.GetVariantionsForFile(ctx, fileName)
+	paths, err := a.versionPaths(ctx, name)
 	if err != nil {
 		return nil, err
 	}
+	if version < 0 || version >= len(paths) {
+		return nil, fmt.Errorf("%w: %d", ErrNoSuchVersion, version)
+	}
 
-	if len(variants) > 0 {
-		sort.Slice(variants, func(i, j int) bool {
-			return variants[i].Date.After(variants[j].Date)
-		})
+	return ioutil.ReadFile(paths[version])
+}
 
-		newestVariant := variants[0]
-		filePath = newestVariant.FileName
+// ListVersions returns all versions of a document, newest first. The original
+// document is dated by its modification time.
+func (a *aDocRepository) ListVersions(ctx context.Context, fileName string) ([]DocumentVersion, error) {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := os.Stat(name.originalPath())
+	if err != nil {
+		return nil, err
 	}
 
-	return ioutil.ReadFile(filePath)
+	variants, err := a.GetVariantionsForFile(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].Date.After(variants[j].Date)
+	})
+
+	var versions []DocumentVersion
+	for i, variant := range variants {
+		metadata, err := readVariantMetadata(variant.FileName)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, DocumentVersion{Version: i, CreatedAt: variant.Date, Author: metadata.Author, VoicePromptID: metadata.VoicePromptID})
+	}
+	return append(versions, DocumentVersion{Version: len(variants), CreatedAt: original.ModTime(), Original: true}), nil
+}
+
+// versionPaths returns the paths of all versions of a document, newest first.
+// The original document in the adocs folder is always the oldest version.
+func (a *aDocRepository) versionPaths(ctx context.Context, name DocumentName) ([]string, error) {
+	variants, err := a.GetVariantionsForFile(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].Date.After(variants[j].Date)
+	})
+
+	var paths []string
+	for _, variant := range variants {
+		paths = append(paths, variant.FileName)
+	}
+	return append(paths, name.originalPath()), nil
+}
+
+// Undo drops the newest variant of a document. The variant is renamed rather
+// than deleted, so the change can still be inspected afterwards.
+func (a *aDocRepository) Undo(ctx context.Context, fileName string) error {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return err
+	}
+	defer a.lock(name)()
+
+	state, err := a.readState(name)
+	if err != nil {
+		return err
+	}
+	if state.Locked() {
+		return ErrDocumentLocked
+	}
+
+	paths, err := a.versionPaths(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(paths) < 2 {
+		return ErrNothingToUndo
+	}
+
+	return os.Rename(paths[0], paths[0]+".undone")
 }
 
-func (a *aDocRepository) SaveVariantForFile(ctx context.Context, fileName string, data []byte) error {
+// SaveVariantForFile saves a new version of a document. The author is the
+// user who made the change, empty if authentication is disabled, and the
+// voice prompt ID names the archived clip it was dictated with, if any.
+func (a *aDocRepository) SaveVariantForFile(ctx context.Context, fileName string, data []byte, author string, voicePromptID string) error {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return err
+	}
 	if err := a.ensureWorkDirExists(); err != nil {
 		return err
 	}
+	defer a.lock(name)()
 
-	timestamp := time.Now().Format("20060102_150405")
-	variantFileName := fmt.Sprintf("%s_%s.adoc", fileName, timestamp)
+	state, err := a.readState(name)
+	if err != nil {
+		return err
+	}
+	if state.Locked() {
+		return ErrDocumentLocked
+	}
+	if err := a.Authorize(ctx, fileName, author, RoleEditor); err != nil {
+		return err
+	}
+
+	filePath := name.variantPath(time.Now())
 
-	filePath := filepath.Join(workDirName, variantFileName)
+	if author != "" || voicePromptID != "" {
+		metadata, err := json.Marshal(variantMetadata{Author: author, VoicePromptID: voicePromptID})
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filePath+".json", metadata, 0644); err != nil {
+			return err
+		}
+	}
 
 	return ioutil.WriteFile(filePath, data, 0644)
 }
 
+func readVariantMetadata(variantPath string) (variantMetadata, error) {
+	var metadata variantMetadata
+	data, err := ioutil.ReadFile(variantPath + ".json")
+	if os.IsNotExist(err) {
+		return metadata, nil
+	} else if err != nil {
+		return metadata, err
+	}
+	err = json.Unmarshal(data, &metadata)
+	return metadata, err
+}
+
 func (a *aDocRepository) ensureWorkDirExists() error {
 	if _, err := os.Stat(workDirName); os.IsNotExist(err) {
 		err = os.Mkdir(workDirName, 0755)
@@ -133,3 +343,126 @@ func (a *aDocRepository) ensureWorkDirExists() error {
 	}
 	return nil
 }
+
+func (a *aDocRepository) GetState(ctx context.Context, fileName string) (DocumentState, error) {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return DocumentState{}, err
+	}
+	return a.readState(name)
+}
+
+// Finalize locks a draft document and stores the exact PDF that was sent, so
+// later renders with a different asciidoctor version can't alter it. The PDF
+// must be rendered from markup, which has to still be the newest version.
+func (a *aDocRepository) Finalize(ctx context.Context, fileName string, markup []byte, pdf []byte) (DocumentState, error) {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return DocumentState{}, err
+	}
+	defer a.lock(name)()
+
+	state, err := a.readState(name)
+	if err != nil {
+		return DocumentState{}, err
+	}
+	if state.Status != StatusDraft {
+		return DocumentState{}, fmt.Errorf("%w: status is %s", ErrDocumentLocked, state.Status)
+	}
+
+	newest, err := a.ReadFile(ctx, fileName)
+	if err != nil {
+		return DocumentState{}, err
+	}
+	if !bytes.Equal(newest, markup) {
+		return DocumentState{}, ErrDocumentChanged
+	}
+
+	if err := a.ensureWorkDirExists(); err != nil {
+		return DocumentState{}, err
+	}
+	if err := ioutil.WriteFile(name.workPath(".final.pdf"), pdf, 0644); err != nil {
+		return DocumentState{}, err
+	}
+
+	hash := sha256.Sum256(pdf)
+	state = DocumentState{
+		Status:      StatusFinal,
+		FinalizedAt: time.Now(),
+		PDFHash:     hex.EncodeToString(hash[:]),
+	}
+	return state, a.saveState(name, state)
+}
+
+func (a *aDocRepository) Archive(ctx context.Context, fileName string) (DocumentState, error) {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return DocumentState{}, err
+	}
+	if _, err := os.Stat(name.originalPath()); err != nil {
+		return DocumentState{}, err
+	}
+	defer a.lock(name)()
+
+	state, err := a.readState(name)
+	if err != nil {
+		return DocumentState{}, err
+	}
+	if state.Status != StatusFinal {
+		return DocumentState{}, fmt.Errorf("%w: only final documents can be archived, status is %s", ErrInvalidTransition, state.Status)
+	}
+
+	state.Status = StatusArchived
+	return state, a.saveState(name, state)
+}
+
+// ReadFinalPDF returns the PDF stored on finalization after checking that it
+// still matches the recorded hash.
+func (a *aDocRepository) ReadFinalPDF(ctx context.Context, fileName string) ([]byte, error) {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := a.readState(name)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Locked() {
+		return nil, fmt.Errorf("document %s is not finalized", name)
+	}
+
+	pdf, err := ioutil.ReadFile(name.workPath(".final.pdf"))
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(pdf)
+	if hex.EncodeToString(hash[:]) != state.PDFHash {
+		return nil, fmt.Errorf("stored PDF of %s does not match its hash", name)
+	}
+	return pdf, nil
+}
+
+func (a *aDocRepository) readState(name DocumentName) (DocumentState, error) {
+	data, err := ioutil.ReadFile(name.workPath(".state.json"))
+	if os.IsNotExist(err) {
+		return DocumentState{Status: StatusDraft}, nil
+	} else if err != nil {
+		return DocumentState{}, err
+	}
+
+	var state DocumentState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return DocumentState{}, err
+	}
+	return state, nil
+}
+
+func (a *aDocRepository) saveState(name DocumentName, state DocumentState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name.workPath(".state.json"), data, 0644)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+func TestDocumentLifecycleErrors(t *testing.T) {
+	inWorkspace(t, map[string]string{"invoice": "= Invoice\n\nText\n"})
+	weavertest.Local.Test(t, func(t *testing.T, documents ADocRepository) {
+		ctx := context.Background()
+
+		if _, err := documents.Archive(ctx, "invoice"); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("archiving a draft returned %v, want ErrInvalidTransition", err)
+		}
+		if _, err := documents.Archive(ctx, "missing"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("archiving a missing document returned %v, want fs.ErrNotExist", err)
+		}
+		if _, err := documents.Finalize(ctx, "../invoice", nil, nil); !errors.Is(err, ErrInvalidDocumentName) {
+			t.Errorf("finalizing ../invoice returned %v, want ErrInvalidDocumentName", err)
+		}
+
+		// A change saved after the PDF was rendered must not be finalized
+		rendered, err := documents.ReadFile(ctx, "invoice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := documents.SaveVariantForFile(ctx, "invoice", []byte("= Invoice\n\nChanged\n"), "", ""); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := documents.Finalize(ctx, "invoice", rendered, []byte("%PDF")); !errors.Is(err, ErrDocumentChanged) {
+			t.Errorf("finalizing stale markup returned %v, want ErrDocumentChanged", err)
+		}
+
+		newest, _ := documents.ReadFile(ctx, "invoice")
+		if _, err := documents.Finalize(ctx, "invoice", newest, []byte("%PDF")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := documents.Finalize(ctx, "invoice", newest, []byte("%PDF")); !errors.Is(err, ErrDocumentLocked) {
+			t.Errorf("finalizing twice returned %v, want ErrDocumentLocked", err)
+		}
+		if err := documents.SaveVariantForFile(ctx, "invoice", []byte("late"), "", ""); !errors.Is(err, ErrDocumentLocked) {
+			t.Errorf("saving a final document returned %v, want ErrDocumentLocked", err)
+		}
+		if state, err := documents.Archive(ctx, "invoice"); err != nil || state.Status != StatusArchived {
+			t.Errorf("archiving a final document returned %v, %v", state.Status, err)
+		}
+	})
+}
+
+func TestWriteStateStatus(t *testing.T) {
+	tests := map[error]int{
+		nil: http.StatusOK,
+		fmt.Errorf("%w: ..", ErrInvalidDocumentName):              http.StatusBadRequest,
+		fmt.Errorf("%w: bob", ErrForbidden):                       http.StatusForbidden,
+		fmt.Errorf("open adocs/missing.adoc: %w", fs.ErrNotExist): http.StatusNotFound,
+		fmt.Errorf("%w: status is final", ErrDocumentLocked):      http.StatusConflict,
+		fmt.Errorf("%w: status is draft", ErrInvalidTransition):   http.StatusConflict,
+		ErrDocumentChanged: http.StatusConflict,
+		fmt.Errorf("%w: total", ErrInvoiceInconsistent): http.StatusUnprocessableEntity,
+	}
+	a := &app{}
+	for err, want := range tests {
+		recorder := httptest.NewRecorder()
+		a.writeState(recorder, DocumentState{Status: StatusFinal}, err)
+		if recorder.Code != want {
+			t.Errorf("writeState(%v) answered %d, want %d", err, recorder.Code, want)
+		}
+	}
+}
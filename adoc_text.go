@@ -0,0 +1,140 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	blockAttributePattern = regexp.MustCompile(`^\[.*\]$`)
+	inlineMarkupPattern   = regexp.MustCompile(`[*_` + "`" + `#]+`)
+)
+
+// markupToText strips the AsciiDoc syntax from a document so that what is
+// left can be read aloud. Attribute references are replaced by their values.
+func markupToText(markup []byte) string {
+	return textWithAttributes(markup, parseHeaderAttributes(markup))
+}
+
+// sectionToText is markupToText for a single section of a document.
+func sectionToText(markup []byte, title string) (string, bool) {
+	section, ok := documentSection(markup, title)
+	if !ok {
+		return "", false
+	}
+	return textWithAttributes(section, parseHeaderAttributes(markup)), true
+}
+
+func textWithAttributes(markup []byte, attributes []Attribute) string {
+	values := make(map[string]string)
+	for _, attribute := range attributes {
+		values[attribute.Name] = attribute.Value
+	}
+
+	var sentences []string
+	for _, line := range strings.Split(string(markup), "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\r"))
+
+		switch {
+		case line == "",
+			strings.HasPrefix(line, "//"),
+			strings.HasPrefix(line, "|==="),
+			strings.HasPrefix(line, "____"),
+			strings.HasPrefix(line, "----"),
+			blockAttributePattern.MatchString(line),
+			attributeLinePattern.MatchString(line):
+			continue
+		}
+
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			line = match[1]
+		}
+		line = strings.TrimLeft(line, ".*-")
+		if strings.HasPrefix(line, "|") {
+			line = strings.Join(splitCells(line), ", ")
+		}
+		line = inlineMarkupPattern.ReplaceAllString(line, "")
+		for name, value := range values {
+			line = strings.ReplaceAll(line, "{"+name+"}", value)
+		}
+
+		if line = strings.TrimSpace(line); line != "" {
+			sentences = append(sentences, line)
+		}
+	}
+
+	return strings.Join(sentences, "\n")
+}
+
+// documentSection returns the part of a document below the heading with the
+// given title, up to the next heading of the same or a higher level.
+func documentSection(markup []byte, title string) ([]byte, bool) {
+	lines := strings.Split(string(markup), "\n")
+	title = strings.ToLower(strings.TrimSpace(title))
+
+	start, level := -1, 0
+	for i, line := range lines {
+		match := headingPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+		headingLevel := len(line) - len(strings.TrimLeft(line, "="))
+		if start == -1 {
+			if strings.ToLower(match[1]) == title {
+				start, level = i, headingLevel
+			}
+			continue
+		}
+		if headingLevel <= level {
+			return []byte(strings.Join(lines[start:i], "\n")), true
+		}
+	}
+
+	if start == -1 {
+		return nil, false
+	}
+	return []byte(strings.Join(lines[start:], "\n")), true
+}
+
+// changeSummary describes which lines of text were removed and added between
+// two versions of a document, in the document's language.
+func changeSummary(oldMarkup []byte, newMarkup []byte) string {
+	oldLines := strings.Split(markupToText(oldMarkup), "\n")
+	newLines := strings.Split(markupToText(newMarkup), "\n")
+
+	removed := missingLines(oldLines, newLines)
+	added := missingLines(newLines, oldLines)
+
+	removedLabel, addedLabel, unchanged := "Removed", "Added", "Nothing changed."
+	if documentLanguage(newMarkup) == "de" {
+		removedLabel, addedLabel, unchanged = "Entfernt", "Hinzugefügt", "Nichts wurde geändert."
+	}
+
+	var summary []string
+	if len(removed) > 0 {
+		summary = append(summary, removedLabel+": "+strings.Join(removed, ". ")+".")
+	}
+	if len(added) > 0 {
+		summary = append(summary, addedLabel+": "+strings.Join(added, ". ")+".")
+	}
+	if len(summary) == 0 {
+		return unchanged
+	}
+	return strings.Join(summary, "\n")
+}
+
+// missingLines returns the lines of a that do not appear in b.
+func missingLines(a []string, b []string) []string {
+	present := make(map[string]bool)
+	for _, line := range b {
+		present[line] = true
+	}
+
+	var missing []string
+	for _, line := range a {
+		if line != "" && !present[line] {
+			missing = append(missing, line)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,732 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	apiPrefix         = "/api/v1"
+	apiDefaultPerPage = 20
+	apiMaxPerPage     = 100
+)
+
+var errInvalidRequest = errors.New("invalid request")
+
+// apiRoute is one operation of the JSON API. The same table registers the
+// handlers and generates the OpenAPI spec, so the two cannot drift apart.
+type apiRoute struct {
+	method  string
+	path    string
+	summary string
+	// query lists the query parameters besides the pagination parameters.
+	query []string
+	// request and response are zero values of the JSON bodies, nil if there
+	// is none. Paginated routes return a page of response items.
+	request   interface{}
+	response  interface{}
+	paginated bool
+	// created answers with 201 instead of 200.
+	created bool
+	// contentType of a body that is not JSON.
+	requestType  string
+	responseType string
+	handler      func(w http.ResponseWriter, r *http.Request) (interface{}, error)
+}
+
+// apiError is the body of every failed API request.
+type apiError struct {
+	Error apiErrorDetail `json:"error"`
+}
+
+type apiErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiPage is one page of a list resource.
+type apiPage struct {
+	Items   interface{} `json:"items"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"perPage"`
+	Total   int         `json:"total"`
+}
+
+// apiFile is returned by handlers that respond with raw bytes.
+type apiFile struct {
+	contentType string
+	data        []byte
+}
+
+type apiDocumentSummary struct {
+	Name   string `json:"name"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"`
+}
+
+type apiDocument struct {
+	Name       string        `json:"name"`
+	Title      string        `json:"title,omitempty"`
+	Language   string        `json:"language,omitempty"`
+	State      DocumentState `json:"state"`
+	Attributes []Attribute   `json:"attributes"`
+	Versions   int           `json:"versions"`
+	Markup     string        `json:"markup"`
+}
+
+type apiVariant struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Original  bool      `json:"original,omitempty"`
+	Markup    string    `json:"markup"`
+}
+
+type apiEditRequest struct {
+	Prompt string `json:"prompt,omitempty"`
+	// Template is the ID of a prompt template used instead of a prompt, with
+	// the Values of its placeholders.
+	Template string            `json:"template,omitempty"`
+	Values   map[string]string `json:"values,omitempty"`
+	// NoCache asks GPT again even if an answer to the same request is cached.
+	NoCache bool `json:"noCache,omitempty"`
+	// VoicePromptID links the new version to the archived clip the prompt
+	// was dictated with, as returned by /transcriptions.
+	VoicePromptID string `json:"voicePromptId,omitempty"`
+}
+
+type apiEdit struct {
+	Prompt  string             `json:"prompt"`
+	Invoice InvoiceReport      `json:"invoice"`
+	Blocked []BlockedDirective `json:"blocked,omitempty"`
+	Cached  bool               `json:"cached"`
+}
+
+type apiTranscriptionForm struct {
+	Audio    []byte `json:"audio"`
+	Language string `json:"language,omitempty"`
+	Document string `json:"document,omitempty"`
+}
+
+type apiTranscription struct {
+	Text          string `json:"text"`
+	VoicePromptID string `json:"voicePromptId,omitempty"`
+}
+
+func (a *app) apiRoutes() []apiRoute {
+	return []apiRoute{
+		{
+			method: http.MethodGet, path: "/documents", summary: "List documents",
+			response: apiDocumentSummary{}, paginated: true,
+			handler: a.apiListDocuments,
+		},
+		{
+			method: http.MethodGet, path: "/documents/{name}", summary: "Get the newest version of a document",
+			response: apiDocument{},
+			handler:  a.apiGetDocument,
+		},
+		{
+			method: http.MethodGet, path: "/documents/{name}/variants", summary: "List the versions of a document, newest first",
+			response: DocumentVersion{}, paginated: true,
+			handler: a.apiListVariants,
+		},
+		{
+			method: http.MethodGet, path: "/documents/{name}/variants/{version}", summary: "Get a version of a document, 0 is the newest",
+			response: apiVariant{},
+			handler:  a.apiGetVariant,
+		},
+		{
+			method: http.MethodGet, path: "/documents/{name}/variants/{version}/pdf", summary: "Render a version of a document",
+			responseType: "application/pdf",
+			handler:      a.apiRenderVariant,
+		},
+		{
+			method: http.MethodPost, path: "/documents/{name}/edits", summary: "Change a document with a prompt",
+			request: apiEditRequest{}, response: apiEdit{}, created: true,
+			handler: a.apiCreateEdit,
+		},
+		{
+			method: http.MethodPost, path: "/documents/{name}/undo", summary: "Drop the newest version of a document",
+			response: apiDocument{},
+			handler:  a.apiUndo,
+		},
+		{
+			method: http.MethodGet, path: "/documents/{name}/attributes", summary: "Get the header attributes of a document",
+			response: []Attribute{},
+			handler:  a.apiGetAttributes,
+		},
+		{
+			method: http.MethodPut, path: "/documents/{name}/attributes", summary: "Set header attributes of a document",
+			request: []Attribute{}, response: []Attribute{},
+			handler: a.apiSetAttributes,
+		},
+		{
+			method: http.MethodGet, path: "/documents/{name}/invoice", summary: "Check the totals of an invoice",
+			response: InvoiceReport{},
+			handler:  a.apiCheckInvoice,
+		},
+		{
+			method: http.MethodGet, path: "/documents/{name}/state", summary: "Get the lifecycle state of a document",
+			response: DocumentState{},
+			handler:  a.apiGetState,
+		},
+		{
+			method: http.MethodPost, path: "/documents/{name}/finalize", summary: "Finalize a document and freeze its PDF",
+			response: DocumentState{},
+			handler:  a.apiFinalize,
+		},
+		{
+			method: http.MethodPost, path: "/documents/{name}/archive", summary: "Archive a finalized document",
+			response: DocumentState{},
+			handler:  a.apiArchive,
+		},
+		{
+			method: http.MethodGet, path: "/documents/{name}/access", summary: "List who may access a document",
+			response: AccessList{},
+			handler:  a.apiGetAccess,
+		},
+		{
+			method: http.MethodPut, path: "/documents/{name}/access/{user}", summary: "Share a document with a user, or with everyone as *",
+			request: shareRequest{}, response: AccessList{},
+			handler: a.apiShare,
+		},
+		{
+			method: http.MethodDelete, path: "/documents/{name}/access/{user}", summary: "Revoke the access of a user",
+			response: AccessList{},
+			handler:  a.apiShare,
+		},
+		{
+			method: http.MethodPost, path: "/documents/{name}/share-links", summary: "Share the PDF of a document through a signed, expiring link",
+			request: shareLinkRequest{}, response: shareLinkResponse{}, created: true,
+			handler: a.apiCreateShareLink,
+		},
+		{
+			method: http.MethodGet, path: "/share-links", summary: "List the share links of the documents you own",
+			response: ShareLink{}, paginated: true,
+			handler: a.apiListShareLinks,
+		},
+		{
+			method: http.MethodDelete, path: "/share-links/{id}", summary: "Revoke a share link",
+			response: ShareLink{},
+			handler:  a.apiRevokeShareLink,
+		},
+		{
+			method: http.MethodGet, path: "/prompt-templates", summary: "List the prompt templates",
+			response: PromptTemplate{}, paginated: true,
+			handler: a.apiListPromptTemplates,
+		},
+		{
+			method: http.MethodPost, path: "/prompt-templates", summary: "Create a prompt template with {{placeholders}}",
+			request: promptTemplateRequest{}, response: PromptTemplate{}, created: true,
+			handler: a.apiCreatePromptTemplate,
+		},
+		{
+			method: http.MethodGet, path: "/prompt-templates/{id}", summary: "Get a prompt template",
+			response: PromptTemplate{},
+			handler:  a.apiGetPromptTemplate,
+		},
+		{
+			method: http.MethodPut, path: "/prompt-templates/{id}", summary: "Change a prompt template",
+			request: promptTemplateRequest{}, response: PromptTemplate{},
+			handler: a.apiUpdatePromptTemplate,
+		},
+		{
+			method: http.MethodDelete, path: "/prompt-templates/{id}", summary: "Delete a prompt template",
+			response: PromptTemplate{},
+			handler:  a.apiDeletePromptTemplate,
+		},
+		{
+			method: http.MethodGet, path: "/usage", summary: "Sum up LLM usage and cost by user, document or day",
+			query:    []string{"group_by", "from", "to", "user", "document"},
+			response: UsageAggregate{}, paginated: true,
+			handler: a.apiUsage,
+		},
+		{
+			method: http.MethodPost, path: "/transcriptions", summary: "Transcribe a voice prompt",
+			request: apiTranscriptionForm{}, requestType: "multipart/form-data", response: apiTranscription{}, created: true,
+			handler: a.apiCreateTranscription,
+		},
+		{
+			method: http.MethodGet, path: "/openapi.json", summary: "Get this specification",
+			handler: a.apiSpec,
+		},
+	}
+}
+
+// registerAPI mounts the JSON API below /api/v1.
+func (a *app) registerAPI(router *mux.Router) {
+	api := router.PathPrefix(apiPrefix).Subrouter()
+	for _, route := range a.apiRoutes() {
+		api.HandleFunc(route.path, a.apiHandler(route)).Methods(route.method)
+	}
+
+	// Short path of the usage report
+	router.HandleFunc("/api/usage", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, apiPrefix+"/usage?"+r.URL.RawQuery, http.StatusTemporaryRedirect)
+	})
+
+	api.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.writeAPIError(w, http.StatusNotFound, "not_found", "no such resource")
+	})
+	api.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	})
+}
+
+func (a *app) apiHandler(route apiRoute) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := route.handler(w, r)
+		if err != nil {
+			status, code := apiStatus(err)
+			if status == http.StatusInternalServerError {
+				a.Logger().Warn("API request failed", "path", r.URL.Path, "err", err)
+			}
+			a.writeAPIError(w, status, code, err.Error())
+			return
+		}
+
+		if file, ok := result.(apiFile); ok {
+			w.Header().Set("Content-Type", file.contentType)
+			if _, err := w.Write(file.data); err != nil {
+				a.Logger().Warn("Error writing response:", err)
+			}
+			return
+		}
+
+		status := http.StatusOK
+		if route.created {
+			status = http.StatusCreated
+		}
+		a.writeAPIJSON(w, status, result)
+	}
+}
+
+func (a *app) writeAPIJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		a.Logger().Warn("Error writing response:", err)
+	}
+}
+
+func (a *app) writeAPIError(w http.ResponseWriter, status int, code string, message string) {
+	a.writeAPIJSON(w, status, apiError{Error: apiErrorDetail{Code: code, Message: message}})
+}
+
+// apiStatus maps the errors of the components to a status and error code.
+func apiStatus(err error) (int, string) {
+	var maxBytesError *http.MaxBytesError
+	switch {
+	case errors.Is(err, errInvalidRequest), errors.Is(err, errInvalidAttribute), errors.Is(err, errInvalidLanguage):
+		return http.StatusBadRequest, "invalid_request"
+	case errors.Is(err, ErrEmptyAudio):
+		return http.StatusBadRequest, "empty_audio"
+	case errors.Is(err, ErrInvalidDocumentName):
+		return http.StatusBadRequest, "invalid_document_name"
+	case errors.Is(err, ErrInvalidRole):
+		return http.StatusBadRequest, "invalid_role"
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized, "unauthenticated"
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden, "forbidden"
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, ErrNoSuchVersion), errors.Is(err, ErrShareLinkInvalid), errors.Is(err, ErrNoSuchTemplate):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrDocumentLocked):
+		return http.StatusConflict, "document_locked"
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrDocumentChanged):
+		return http.StatusConflict, "invalid_state"
+	case errors.Is(err, ErrNothingToUndo):
+		return http.StatusConflict, "nothing_to_undo"
+	case errors.Is(err, ErrAudioTooLarge), errors.As(err, &maxBytesError):
+		return http.StatusRequestEntityTooLarge, "audio_too_large"
+	case errors.Is(err, ErrUnsupportedAudio):
+		return http.StatusUnsupportedMediaType, "unsupported_audio"
+	case errors.Is(err, ErrInvoiceInconsistent):
+		return http.StatusUnprocessableEntity, "invoice_inconsistent"
+	case errors.Is(err, ErrProtectedContent):
+		return http.StatusUnprocessableEntity, "protected_content"
+	case errors.Is(err, ErrLockedContent):
+		return http.StatusUnprocessableEntity, "locked_content"
+	case errors.Is(err, ErrIncompleteChange):
+		return http.StatusUnprocessableEntity, "incomplete_change"
+	case errors.Is(err, ErrBudgetExceeded):
+		return budgetStatus(err), "budget_exceeded"
+	case errors.Is(err, ErrRemoteUnavailable):
+		return http.StatusServiceUnavailable, "remote_unavailable"
+	default:
+		return http.StatusInternalServerError, "internal"
+	}
+}
+
+// paginate cuts the page requested by the page and per_page query parameters
+// out of a list.
+func paginate[T any](r *http.Request, items []T) (apiPage, error) {
+	page, err := queryInt(r, "page", 1)
+	if err != nil || page < 1 {
+		return apiPage{}, fmt.Errorf("%w: page must be a positive number", errInvalidRequest)
+	}
+	perPage, err := queryInt(r, "per_page", apiDefaultPerPage)
+	if err != nil || perPage < 1 || perPage > apiMaxPerPage {
+		return apiPage{}, fmt.Errorf("%w: per_page must be between 1 and %d", errInvalidRequest, apiMaxPerPage)
+	}
+
+	start := minInt((page-1)*perPage, len(items))
+	end := minInt(start+perPage, len(items))
+	pageItems := items[start:end]
+	if pageItems == nil {
+		pageItems = []T{}
+	}
+	return apiPage{Items: pageItems, Page: page, PerPage: perPage, Total: len(items)}, nil
+}
+
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func pathVersion(r *http.Request) (int, error) {
+	version, err := strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil {
+		return 0, fmt.Errorf("%w: version must be a number", errInvalidRequest)
+	}
+	return version, nil
+}
+
+func (a *app) apiListDocuments(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	fileNames, err := a.aDocRepository.Get().GetVisibleFiles(ctx, requestUser(r).Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []apiDocumentSummary
+	for _, fileName := range fileNames {
+		content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+		if err != nil {
+			return nil, err
+		}
+		state, err := a.aDocRepository.Get().GetState(ctx, fileName)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, apiDocumentSummary{Name: fileName, Title: documentTitle(content), Status: state.Status})
+	}
+	return paginate(r, documents)
+}
+
+func (a *app) apiGetDocument(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.apiDocument(r, mux.Vars(r)["name"])
+}
+
+func (a *app) apiDocument(r *http.Request, fileName string) (apiDocument, error) {
+	ctx := r.Context()
+	content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+	if err != nil {
+		return apiDocument{}, err
+	}
+	state, err := a.aDocRepository.Get().GetState(ctx, fileName)
+	if err != nil {
+		return apiDocument{}, err
+	}
+	versions, err := a.aDocRepository.Get().ListVersions(ctx, fileName)
+	if err != nil {
+		return apiDocument{}, err
+	}
+
+	return apiDocument{
+		Name:       fileName,
+		Title:      documentTitle(content),
+		Language:   documentLanguage(content),
+		State:      state,
+		Attributes: parseHeaderAttributes(content),
+		Versions:   len(versions),
+		Markup:     string(content),
+	}, nil
+}
+
+func (a *app) apiListVariants(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	versions, err := a.aDocRepository.Get().ListVersions(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		return nil, err
+	}
+	return paginate(r, versions)
+}
+
+func (a *app) apiGetVariant(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	fileName := mux.Vars(r)["name"]
+	version, err := pathVersion(r)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := a.aDocRepository.Get().ListVersions(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if version < 0 || version >= len(versions) {
+		return nil, fmt.Errorf("%w: %d", ErrNoSuchVersion, version)
+	}
+	content, err := a.aDocRepository.Get().ReadVersion(ctx, fileName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiVariant{
+		Version:   version,
+		CreatedAt: versions[version].CreatedAt,
+		Original:  versions[version].Original,
+		Markup:    string(content),
+	}, nil
+}
+
+func (a *app) apiRenderVariant(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	version, err := pathVersion(r)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf, hash, err := a.renderPDF(r.Context(), mux.Vars(r)["name"], version)
+	if err != nil {
+		return nil, err
+	}
+	if hash != "" {
+		w.Header().Set("X-Content-SHA256", hash)
+	}
+	return apiFile{contentType: "application/pdf", data: pdf}, nil
+}
+
+func (a *app) apiCreateEdit(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	var request apiEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidRequest, err)
+	}
+	prompt, err := a.editPrompt(r, mux.Vars(r)["name"], request.Prompt, request.Template, request.Values)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return nil, fmt.Errorf("%w: prompt is empty", errInvalidRequest)
+	}
+
+	change, err := a.changeDocument(r.Context(), mux.Vars(r)["name"], prompt, requestUser(r).Name, request.VoicePromptID, request.NoCache)
+	if err != nil {
+		return nil, err
+	}
+	return apiEdit{Prompt: prompt, Invoice: change.Invoice, Blocked: change.Blocked, Cached: change.Cached}, nil
+}
+
+func (a *app) apiUndo(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	fileName := mux.Vars(r)["name"]
+	if err := a.aDocRepository.Get().Undo(r.Context(), fileName); err != nil {
+		return nil, err
+	}
+	return a.apiDocument(r, fileName)
+}
+
+func (a *app) apiGetAttributes(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	content, err := a.aDocRepository.Get().ReadFile(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		return nil, err
+	}
+	return parseHeaderAttributes(content), nil
+}
+
+func (a *app) apiSetAttributes(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	var attributes []Attribute
+	if err := json.NewDecoder(r.Body).Decode(&attributes); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidRequest, err)
+	}
+	return a.setAttributes(r.Context(), mux.Vars(r)["name"], attributes, requestUser(r).Name)
+}
+
+func (a *app) apiCheckInvoice(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	content, err := a.aDocRepository.Get().ReadFile(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		return nil, err
+	}
+	return a.invoiceService.Get().Check(r.Context(), content)
+}
+
+func (a *app) apiGetState(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	fileName := mux.Vars(r)["name"]
+	if _, err := a.aDocRepository.Get().ReadFile(r.Context(), fileName); err != nil {
+		return nil, err
+	}
+	return a.aDocRepository.Get().GetState(r.Context(), fileName)
+}
+
+func (a *app) apiFinalize(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.finalizeDocument(r.Context(), mux.Vars(r)["name"], requestUser(r).Name)
+}
+
+func (a *app) apiArchive(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.aDocRepository.Get().Archive(r.Context(), mux.Vars(r)["name"])
+}
+
+func (a *app) apiGetAccess(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.aDocRepository.Get().GetAccess(r.Context(), mux.Vars(r)["name"])
+}
+
+func (a *app) apiShare(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	var request shareRequest
+	if r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidRequest, err)
+		}
+		if request.Role == "" {
+			return nil, ErrInvalidRole
+		}
+	}
+	return a.aDocRepository.Get().Share(r.Context(), mux.Vars(r)["name"], mux.Vars(r)["user"], request.Role)
+}
+
+func (a *app) apiCreateShareLink(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	var request shareLinkRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidRequest, err)
+		}
+	}
+	return a.createShareLink(r, mux.Vars(r)["name"], request)
+}
+
+func (a *app) apiListShareLinks(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	links, err := a.visibleShareLinks(r)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(r, links)
+}
+
+func (a *app) apiRevokeShareLink(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.revokeShareLink(r, mux.Vars(r)["id"])
+}
+
+func (a *app) apiListPromptTemplates(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	templates, err := a.promptTemplates.Get().List(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return paginate(r, templates)
+}
+
+func (a *app) apiCreatePromptTemplate(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.savePromptTemplate(r, "")
+}
+
+func (a *app) apiGetPromptTemplate(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.promptTemplates.Get().Get(r.Context(), mux.Vars(r)["id"])
+}
+
+func (a *app) apiUpdatePromptTemplate(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.savePromptTemplate(r, mux.Vars(r)["id"])
+}
+
+func (a *app) apiDeletePromptTemplate(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return a.deletePromptTemplate(r, mux.Vars(r)["id"])
+}
+
+// apiUsage aggregates the usage of the current month by default. Only owners
+// of all documents see the usage of others.
+func (a *app) apiUsage(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	query := UsageQuery{
+		GroupBy:  r.URL.Query().Get("group_by"),
+		User:     r.URL.Query().Get("user"),
+		Document: r.URL.Query().Get("document"),
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = "day"
+	}
+
+	now := time.Now()
+	query.From = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	for name, t := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if value := r.URL.Query().Get(name); value != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", value, now.Location())
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be a date like 2023-06-29", errInvalidRequest, name)
+			}
+			*t = parsed
+		}
+	}
+	if !query.To.IsZero() {
+		query.To = query.To.AddDate(0, 0, 1) // include the whole day
+	}
+
+	err := a.authorizeRequest(r, "", RoleOwner)
+	if errors.Is(err, ErrForbidden) {
+		if query.User != "" && query.User != requestUser(r).Name {
+			return nil, err
+		}
+		query.User = requestUser(r).Name
+	} else if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := a.usageLedger.Get().Summarize(r.Context(), query)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(r, aggregates)
+}
+
+// budgetStatus asks for payment once the user budget is used up and to come
+// back later once the one of a document is.
+func budgetStatus(err error) int {
+	if errors.Is(err, ErrDocumentBudgetExceeded) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusPaymentRequired
+}
+
+func (a *app) apiCreateTranscription(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, 32<<20)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", errInvalidRequest, err)
+	}
+
+	document, err := canonicalDocument(r.FormValue("document"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.authorizeRequest(r, document, RoleEditor); err != nil {
+		return nil, err
+	}
+
+	file, _, err := r.FormFile("audio")
+	if err != nil {
+		return nil, fmt.Errorf("%w: audio is missing", errInvalidRequest)
+	}
+	defer file.Close()
+
+	audio, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	text, voicePromptID, err := a.transcribe(r.Context(), audio, r.FormValue("language"), document)
+	if err != nil {
+		return nil, err
+	}
+	return apiTranscription{Text: text, VoicePromptID: voicePromptID}, nil
+}
+
+func (a *app) apiSpec(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return openAPISpec(a.apiRoutes()), nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEmptyAudio       = errors.New("audio clip is empty")
+	ErrAudioTooLarge    = errors.New("audio clip is too large")
+	ErrUnsupportedAudio = errors.New("unsupported audio format, expected WAV, WebM, OGG or MP3")
+)
+
+type audioFormat struct {
+	name        string
+	extension   string
+	contentType string
+}
+
+var (
+	audioFormatWAV  = audioFormat{name: "wav", extension: "wav", contentType: "audio/wav"}
+	audioFormatWebM = audioFormat{name: "webm", extension: "webm", contentType: "audio/webm"}
+	audioFormatOGG  = audioFormat{name: "ogg", extension: "ogg", contentType: "audio/ogg"}
+	audioFormatMP3  = audioFormat{name: "mp3", extension: "mp3", contentType: "audio/mpeg"}
+)
+
+func audioFormatByName(name string) (audioFormat, bool) {
+	for _, format := range []audioFormat{audioFormatWAV, audioFormatWebM, audioFormatOGG, audioFormatMP3} {
+		if format.name == name {
+			return format, true
+		}
+	}
+	return audioFormat{}, false
+}
+
+// detectAudioFormat looks at the magic bytes of a clip to find its container,
+// regardless of the file name the browser sent.
+func detectAudioFormat(audio []byte) (audioFormat, error) {
+	switch {
+	case len(audio) == 0:
+		return audioFormat{}, ErrEmptyAudio
+	case len(audio) >= 12 && bytes.Equal(audio[0:4], []byte("RIFF")) && bytes.Equal(audio[8:12], []byte("WAVE")):
+		return audioFormatWAV, nil
+	case bytes.HasPrefix(audio, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return audioFormatWebM, nil
+	case bytes.HasPrefix(audio, []byte("OggS")):
+		return audioFormatOGG, nil
+	case bytes.HasPrefix(audio, []byte("ID3")):
+		return audioFormatMP3, nil
+	case len(audio) >= 2 && audio[0] == 0xFF && audio[1]&0xE0 == 0xE0:
+		// MPEG audio frame sync without an ID3 tag
+		return audioFormatMP3, nil
+	default:
+		return audioFormat{}, ErrUnsupportedAudio
+	}
+}
+
+// audioDuration returns the length of a clip. WAV headers are read directly,
+// other formats need ffprobe. It returns 0 if the length is unknown.
+func audioDuration(ctx context.Context, audio []byte, format audioFormat) time.Duration {
+	if format == audioFormatWAV {
+		return wavDuration(audio)
+	}
+
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "csv=p=0", "-i", "pipe:0")
+	cmd.Stdin = bytes.NewReader(audio)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// wavDuration walks the RIFF chunks to find the byte rate and data size.
+func wavDuration(audio []byte) time.Duration {
+	var byteRate, dataSize uint32
+	for offset := 12; offset+8 <= len(audio); {
+		id := string(audio[offset : offset+4])
+		size := binary.LittleEndian.Uint32(audio[offset+4 : offset+8])
+		switch id {
+		case "fmt ":
+			if offset+20 <= len(audio) {
+				byteRate = binary.LittleEndian.Uint32(audio[offset+16 : offset+20])
+			}
+		case "data":
+			dataSize = size
+			// Recorders that stream WAV write a size of 0 or -1
+			if dataSize == 0 || dataSize == 0xFFFFFFFF || int(dataSize) > len(audio)-offset-8 {
+				dataSize = uint32(len(audio) - offset - 8)
+			}
+		}
+		if id == "data" {
+			break
+		}
+		offset += 8 + int(size) + int(size%2)
+	}
+
+	if byteRate == 0 {
+		return 0
+	}
+	return time.Duration(float64(dataSize) / float64(byteRate) * float64(time.Second))
+}
+
+// transcodeAudio converts a clip with ffmpeg. WAV output is 16 kHz mono,
+// which is what whisper.cpp expects; OGG and MP3 use low bitrates that are
+// plenty for speech.
+func transcodeAudio(ctx context.Context, audio []byte, target audioFormat) ([]byte, error) {
+	args := []string{"-hide_banner", "-loglevel", "error", "-i", "pipe:0", "-vn"}
+	switch target {
+	case audioFormatWAV:
+		args = append(args, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", "-f", "wav")
+	case audioFormatOGG:
+		args = append(args, "-ac", "1", "-c:a", "libopus", "-b:a", "32k", "-f", "ogg")
+	case audioFormatWebM:
+		args = append(args, "-ac", "1", "-c:a", "libopus", "-b:a", "32k", "-f", "webm")
+	case audioFormatMP3:
+		args = append(args, "-ac", "1", "-c:a", "libmp3lame", "-b:a", "48k", "-f", "mp3")
+	}
+	args = append(args, "pipe:1")
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdin = bytes.NewReader(audio)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to transcode audio to %s: %v: %s", target.name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
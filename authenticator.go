@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+const (
+	AuthMethodAPIKey  = "api_key"
+	AuthMethodSession = "session"
+	AuthMethodOIDC    = "oidc"
+
+	defaultSessionHours = 12
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrOIDCDisabled    = errors.New("OIDC login is not configured")
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, credentials Credentials) (User, error)
+	IssueSession(ctx context.Context, user User) (string, error)
+	OIDCEnabled(ctx context.Context) (bool, error)
+	OIDCLoginURL(ctx context.Context, state string, nonce string) (string, error)
+	OIDCExchange(ctx context.Context, code string, nonce string) (User, error)
+}
+
+// Credentials are what a request presents to prove who sent it.
+type Credentials struct {
+	weaver.AutoMarshal
+	APIKey  string
+	Session string
+}
+
+// User is the authenticated sender of a request. Its name is recorded on
+// the variants the user saves.
+type User struct {
+	weaver.AutoMarshal
+	Name   string `json:"name"`
+	Method string `json:"method"`
+}
+
+type authConfig struct {
+	// Disabled turns authentication off. Only use this on a machine nobody
+	// else can reach.
+	Disabled bool `toml:"disabled"`
+	// APIKeys are the SHA-256 hashes of the keys that are accepted. Without
+	// keys and without OIDC a one-time key is logged on startup.
+	APIKeys []apiKeyConfig `toml:"api_keys"`
+	// SessionHours is how long a browser login lasts, 12 hours by default.
+	SessionHours int `toml:"session_hours"`
+	// OIDC lets browser users log in with an OpenID Connect provider.
+	OIDC oidcConfig `toml:"oidc"`
+}
+
+type apiKeyConfig struct {
+	User   string `toml:"user"`
+	SHA256 string `toml:"sha256"`
+}
+
+// sessionClaims is the signed content of a session cookie. Sessions are not
+// stored, so one cannot be revoked before it expires; only a new session
+// secret ends all of them.
+type sessionClaims struct {
+	Name    string `json:"name"`
+	Method  string `json:"method"`
+	Expires int64  `json:"exp"`
+}
+
+// Implementation of the Authenticator component.
+type authenticator struct {
+	weaver.Implements[Authenticator]
+	weaver.WithConfig[authConfig]
+	apiKeys       []apiKeyConfig
+	sessionSecret []byte
+
+	mu       sync.Mutex
+	provider *oidcProvider
+}
+
+func (a *authenticator) Init(context.Context) error {
+	config := a.Config()
+
+	a.apiKeys = config.APIKeys
+	for _, key := range a.apiKeys {
+		if _, err := hex.DecodeString(key.SHA256); err != nil || len(key.SHA256) != 2*sha256.Size {
+			return fmt.Errorf("api key of %s is no SHA-256 hex digest", key.User)
+		}
+		if key.User == "" {
+			return fmt.Errorf("api key %s has no user", key.SHA256)
+		}
+	}
+
+	// SUDOCU_SESSION_SECRET keeps sessions valid across restarts. Without it
+	// every restart logs everybody out.
+	a.sessionSecret = []byte(os.Getenv("SUDOCU_SESSION_SECRET"))
+	if len(a.sessionSecret) == 0 {
+		a.sessionSecret = make([]byte, 32)
+		if _, err := rand.Read(a.sessionSecret); err != nil {
+			return err
+		}
+	}
+
+	if config.OIDC.Issuer != "" && (config.OIDC.ClientID == "" || config.OIDC.RedirectURL == "") {
+		return fmt.Errorf("oidc needs client_id and redirect_url")
+	}
+
+	if !config.Disabled && len(a.apiKeys) == 0 && config.OIDC.Issuer == "" {
+		key, err := randomToken(32)
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256([]byte(key))
+		a.apiKeys = []apiKeyConfig{{User: "admin", SHA256: hex.EncodeToString(hash[:])}}
+		a.Logger().Warn("No API keys configured, log in with this one-time key", "user", "admin", "key", key)
+	}
+
+	return nil
+}
+
+// Authenticate returns the user an API key or a session cookie belongs to.
+// It returns an anonymous user when authentication is disabled.
+func (a *authenticator) Authenticate(ctx context.Context, credentials Credentials) (User, error) {
+	if a.Config().Disabled {
+		return User{}, nil
+	}
+
+	if credentials.APIKey != "" {
+		return a.authenticateKey(credentials.APIKey)
+	}
+	if credentials.Session != "" {
+		return a.verifySession(credentials.Session)
+	}
+	return User{}, ErrUnauthenticated
+}
+
+func (a *authenticator) authenticateKey(key string) (User, error) {
+	hash := sha256.Sum256([]byte(key))
+	presented := hex.EncodeToString(hash[:])
+
+	for _, apiKey := range a.apiKeys {
+		if hmac.Equal([]byte(presented), []byte(strings.ToLower(apiKey.SHA256))) {
+			return User{Name: apiKey.User, Method: AuthMethodAPIKey}, nil
+		}
+	}
+	return User{}, fmt.Errorf("%w: unknown API key", ErrUnauthenticated)
+}
+
+// IssueSession returns a signed session token for a browser login.
+func (a *authenticator) IssueSession(ctx context.Context, user User) (string, error) {
+	hours := a.Config().SessionHours
+	if hours <= 0 {
+		hours = defaultSessionHours
+	}
+
+	method := user.Method
+	if method == AuthMethodAPIKey {
+		method = AuthMethodSession
+	}
+	payload, err := json.Marshal(sessionClaims{
+		Name:    user.Name,
+		Method:  method,
+		Expires: time.Now().Add(time.Duration(hours) * time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(a.sign(encoded)), nil
+}
+
+func (a *authenticator) verifySession(token string) (User, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return User{}, fmt.Errorf("%w: malformed session", ErrUnauthenticated)
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, a.sign(encoded)) {
+		return User{}, fmt.Errorf("%w: invalid session", ErrUnauthenticated)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return User{}, fmt.Errorf("%w: malformed session", ErrUnauthenticated)
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return User{}, fmt.Errorf("%w: malformed session", ErrUnauthenticated)
+	}
+	if time.Now().Unix() > claims.Expires {
+		return User{}, fmt.Errorf("%w: session expired", ErrUnauthenticated)
+	}
+
+	return User{Name: claims.Name, Method: claims.Method}, nil
+}
+
+func (a *authenticator) sign(data string) []byte {
+	mac := hmac.New(sha256.New, a.sessionSecret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func (a *authenticator) OIDCEnabled(ctx context.Context) (bool, error) {
+	return a.Config().OIDC.Issuer != "", nil
+}
+
+// OIDCLoginURL returns where to send the browser to log in with the OIDC
+// provider.
+func (a *authenticator) OIDCLoginURL(ctx context.Context, state string, nonce string) (string, error) {
+	provider, err := a.oidcProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	return provider.authorizationURL(a.Config().OIDC, state, nonce), nil
+}
+
+// OIDCExchange redeems the code the provider redirected the browser back
+// with and returns the user of the verified ID token.
+func (a *authenticator) OIDCExchange(ctx context.Context, code string, nonce string) (User, error) {
+	provider, err := a.oidcProvider(ctx)
+	if err != nil {
+		return User{}, err
+	}
+
+	config := a.Config().OIDC
+	claims, err := provider.exchange(ctx, config, code, nonce)
+	if err != nil {
+		return User{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	userClaim := config.UserClaim
+	if userClaim == "" {
+		userClaim = "email"
+	}
+	name, _ := claims[userClaim].(string)
+	if name == "" {
+		name, _ = claims["sub"].(string)
+	}
+	if name == "" {
+		return User{}, fmt.Errorf("%w: ID token names no user", ErrUnauthenticated)
+	}
+	return User{Name: name, Method: AuthMethodOIDC}, nil
+}
+
+// oidcProvider discovers the endpoints of the provider on first use.
+func (a *authenticator) oidcProvider(ctx context.Context) (*oidcProvider, error) {
+	if a.Config().OIDC.Issuer == "" {
+		return nil, ErrOIDCDisabled
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.provider == nil {
+		provider, err := discoverOIDCProvider(ctx, a.Config().OIDC.Issuer)
+		if err != nil {
+			return nil, err
+		}
+		a.provider = provider
+	}
+	return a.provider, nil
+}
+
+func randomToken(size int) (string, error) {
+	token := make([]byte, size)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
@@ -8,17 +8,65 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/ServiceWeaver/weaver"
 )
 
 type ChatGPTRepository interface {
-	ChangeMarkup(ctx context.Context, oldMarkup string, prompt string) ([]byte, error)
+	ChangeMarkup(ctx context.Context, oldMarkup string, prompt string, bypassCache bool) ([]byte, TokenUsage, error)
+	EstimateUsage(ctx context.Context, oldMarkup string, prompt string) (TokenUsage, error)
+}
+
+type chatGPTConfig struct {
+	// BaseURL of an OpenAI compatible API.
+	BaseURL string `toml:"base_url"`
+	// Model used for changes, "gpt-3.5-turbo" by default.
+	Model string `toml:"model"`
+	// ContextTokens is the context window of the model, 4096 by default.
+	// Longer documents are changed in parts.
+	ContextTokens int `toml:"context_tokens"`
+	// ProtectedAttributes are header attributes a change may only remove or
+	// alter if the prompt mentions them, vat_number, iban and bic by default.
+	ProtectedAttributes []string `toml:"protected_attributes"`
+	// ProtectedSections are section titles a change may only remove if the
+	// prompt mentions them.
+	ProtectedSections []string `toml:"protected_sections"`
+	// ProtectedAliases are further words that count as mentioning a protected
+	// attribute or section, e.g. {"vat_number" = ["ust-id"]}. They should
+	// name the content, as a prompt using any of them may change it.
+	ProtectedAliases map[string][]string `toml:"protected_aliases"`
+	// OnLockedChange is either "restore" (default) to put back locked regions
+	// and attributes GPT changed, or "reject" to refuse the change.
+	OnLockedChange string `toml:"on_locked_change"`
+	// Outbound configures timeouts, retries and the circuit breaker.
+	Outbound outboundConfig `toml:"outbound"`
+	// CacheMinutes keeps answers to identical requests for that long, 0
+	// disables the cache.
+	CacheMinutes int `toml:"cache_minutes"`
 }
 
 // Implementation of the PDFGenerator component.
 type chatGPTRepository struct {
 	weaver.Implements[ChatGPTRepository]
+	weaver.WithConfig[chatGPTConfig]
+	outbound *outboundClient
+	cache    *responseCache
+}
+
+func (c *chatGPTRepository) Init(context.Context) error {
+	c.outbound = newOutboundClient(c.Config().Outbound, c.Logger())
+	c.cache = &responseCache{ttl: time.Duration(c.Config().CacheMinutes) * time.Minute}
+	if c.cache.ttl > 0 {
+		c.cache.sweep()
+	}
+	switch c.Config().OnLockedChange {
+	case "", "restore", "reject":
+		return nil
+	default:
+		return fmt.Errorf("invalid on_locked_change %q, expected restore or reject", c.Config().OnLockedChange)
+	}
 }
 
 type ChatGPTRequest struct {
@@ -49,74 +97,213 @@ type Choice struct {
 	Message struct {
 		Content string `json:"content"`
 	} `json:"message"`
+	FinishReason string `json:"finish_reason"`
 }
 
-func (c *chatGPTRepository) ChangeMarkup(ctx context.Context, oldMarkup string, prompt string) ([]byte, error) {
+// ChangeMarkup lets GPT apply a prompt to a document. Documents too long for
+// the model are changed section by section and put back together. Locked
+// regions and attributes are restored or the change fails with
+// ErrLockedContent, changes that drop protected content the prompt did not ask
+// about fail with ErrProtectedContent, and answers that were cut off or lost
+// sections fail with ErrIncompleteChange. With the cache enabled, an earlier
+// answer to the same request is reused unless bypassCache is set.
+func (c *chatGPTRepository) ChangeMarkup(ctx context.Context, oldMarkup string, prompt string, bypassCache bool) ([]byte, TokenUsage, error) {
+	model, contextTokens, overhead, chunks, err := c.plan(oldMarkup, prompt)
+	if err != nil {
+		return nil, TokenUsage{}, err
+	}
+	if len(chunks) > 1 {
+		c.Logger().Info("Changing long document in parts", "parts", len(chunks), "tokens", estimateTokens(oldMarkup))
+	}
+
+	usage := TokenUsage{Model: model, Cached: true}
+	var changed []string
+	for i, chunk := range chunks {
+		part := ""
+		if len(chunks) > 1 {
+			part = fmt.Sprintf("%d of %d", i+1, len(chunks))
+		}
+		maxTokens := contextTokens - overhead - estimateTokens(chunk)
+		if maxTokens < estimateTokens(chunk) {
+			return nil, usage, fmt.Errorf("%w: a paragraph of %d tokens is too long for the model", errInvalidRequest, estimateTokens(chunk))
+		}
+		content, chunkUsage, err := c.changeChunk(ctx, model, maxTokens, chunk, prompt, part, bypassCache)
+		usage.PromptTokens += chunkUsage.PromptTokens
+		usage.CompletionTokens += chunkUsage.CompletionTokens
+		usage.Cached = usage.Cached && chunkUsage.Cached
+		if err == nil && part != "" {
+			err = checkChunk(chunk, content, prompt, part)
+		}
+		if err != nil {
+			c.Logger().Warn("Rejected change", "err", err)
+			return nil, usage, err
+		}
+		changed = append(changed, content)
+	}
+
+	content := changed[0]
+	if len(changed) > 1 {
+		content = joinChunks(changed)
+	}
+
+	newMarkup, violations, err := enforceLocks([]byte(oldMarkup), []byte(content), c.Config().OnLockedChange != "reject")
+	if err != nil {
+		c.Logger().Warn("Rejected change", "err", err)
+		return nil, usage, err
+	}
+	if len(violations) > 0 {
+		c.Logger().Warn("Restored locked content", "changed", strings.Join(violations, ", "))
+	}
+
+	if err := checkProtectedContent([]byte(oldMarkup), newMarkup, prompt, c.Config()); err != nil {
+		c.Logger().Warn("Rejected change", "err", err)
+		return nil, usage, err
+	}
+	return newMarkup, usage, nil
+}
+
+// EstimateUsage guesses the tokens a change will use, before any GPT call is
+// made. Every part is sent with the instructions and prompt, and answered
+// with about as many tokens as it has. Cached answers are not considered.
+func (c *chatGPTRepository) EstimateUsage(ctx context.Context, oldMarkup string, prompt string) (TokenUsage, error) {
+	model, _, overhead, chunks, err := c.plan(oldMarkup, prompt)
+	if err != nil {
+		return TokenUsage{}, err
+	}
+
+	usage := TokenUsage{Model: model}
+	for _, chunk := range chunks {
+		usage.PromptTokens += overhead + estimateTokens(chunk)
+		usage.CompletionTokens += estimateTokens(chunk)
+	}
+	return usage, nil
+}
+
+// plan returns the model, its context window, the tokens taken by the
+// instructions and prompt, and the parts a document is changed in.
+func (c *chatGPTRepository) plan(oldMarkup string, prompt string) (string, int, int, []string, error) {
+	model := c.Config().Model
+	if model == "" {
+		model = "gpt-3.5-turbo"
+	}
+	contextTokens := c.Config().ContextTokens
+	if contextTokens == 0 {
+		contextTokens = 4096
+	}
+
+	// The answer is about as long as the part it changes, leave it some room
+	// to grow
+	overhead := estimateTokens(changeInstructions+partInstructions+prompt) + 100
+	limit := (contextTokens - overhead) * 2 / 5
+	if limit < 256 {
+		return "", 0, 0, nil, fmt.Errorf("%w: prompt is too long for the model", errInvalidRequest)
+	}
+	return model, contextTokens, overhead, splitMarkup(oldMarkup, limit), nil
+}
+
+// changeChunk lets GPT change a document or one part of it, or takes the
+// answer from the cache.
+func (c *chatGPTRepository) changeChunk(ctx context.Context, model string, maxTokens int, markup string, prompt string, part string, bypassCache bool) (string, TokenUsage, error) {
 	request := ChatGPTRequest{
-		Model: "gpt-3.5-turbo",
-		Messages: []Message{
-			{
-				Role:    "system",
-				Content: "Change this ascii-doc based on the user input:\n" + oldMarkup,
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+		Model:            model,
 		Temperature:      1,
-		MaxTokens:        2048,
+		MaxTokens:        maxTokens,
 		TopP:             1,
 		FrequencyPenalty: 0,
 		PresencePenalty:  0,
 	}
 
+	key := c.cache.key(request, markup, prompt, part)
+	if c.cache.ttl > 0 && !bypassCache {
+		if content, ok := c.cache.get(key); ok {
+			c.Logger().Info("Using cached response", "key", key)
+			return content, TokenUsage{Model: model, Cached: true}, nil
+		}
+	}
+
+	messages, tag, err := changeMessages(markup, prompt, part)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	request.Messages = messages
+
+	content, usage, err := c.complete(ctx, request)
+	if err != nil {
+		return "", usage, err
+	}
+	content = stripDocumentTags(content, tag)
+	if c.cache.ttl > 0 {
+		if err := c.cache.put(key, content); err != nil {
+			c.Logger().Warn("Failed to cache response", "err", err)
+		}
+	}
+	return content, usage, nil
+}
+
+// complete sends a request to the chat completions API and returns the first
+// answer with the tokens it used.
+func (c *chatGPTRepository) complete(ctx context.Context, request ChatGPTRequest) (string, TokenUsage, error) {
 	requestBody, err := json.Marshal(request)
 	if err != nil {
-		return nil, err
+		return "", TokenUsage{}, err
 	}
 
+	baseURL := c.Config().BaseURL
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
 	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+	if apiKey == "" && baseURL == defaultOpenAIBaseURL {
+		return "", TokenUsage{}, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
 	}
 
 	c.Logger().Info("Seding request: ", string(requestBody))
 
-	url := "https://api.openai.com/v1/chat/completions"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	url := strings.TrimSuffix(baseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(requestBody))
 	if err != nil {
-		return nil, err
+		return "", TokenUsage{}, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
 
-	client := http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.outbound.Do(req)
 	if err != nil {
-		return nil, err
+		return "", TokenUsage{}, err
 	}
 	defer resp.Body.Close()
 
 	responseBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return "", TokenUsage{}, err
 	}
 
 	c.Logger().Info("Got response: ", string(responseBody))
+	if resp.StatusCode != http.StatusOK {
+		return "", TokenUsage{}, fmt.Errorf("ChatGPT request failed with status %d: %s", resp.StatusCode, string(responseBody))
+	}
 
 	var chatGPTResponse ChatGPTResponse
 	err = json.Unmarshal(responseBody, &chatGPTResponse)
 	if err != nil {
-		return nil, err
+		return "", TokenUsage{}, err
 	}
 
-	c.Logger().Info("Total Token Usage", chatGPTResponse.Usage.TotalTokens, ", estimated amount", float64(chatGPTResponse.Usage.TotalTokens)/1000*0.2)
-
-	if len(chatGPTResponse.Choices) > 0 {
-		return []byte(chatGPTResponse.Choices[0].Message.Content), nil
+	// Tokens are paid for even if the change is rejected later
+	usage := TokenUsage{
+		Model:            request.Model,
+		PromptTokens:     chatGPTResponse.Usage.PromptTokens,
+		CompletionTokens: chatGPTResponse.Usage.CompletionTokens,
 	}
 
-	return nil, fmt.Errorf("empty response from ChatGPT")
+	if len(chatGPTResponse.Choices) == 0 {
+		return "", usage, fmt.Errorf("empty response from ChatGPT")
+	}
+	if chatGPTResponse.Choices[0].FinishReason == "length" {
+		return "", usage, fmt.Errorf("%w: the answer was cut off after %d tokens", ErrIncompleteChange, usage.CompletionTokens)
+	}
+	return chatGPTResponse.Choices[0].Message.Content, usage, nil
 }
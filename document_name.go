@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxDocumentNameLength = 100
+	variantTimeLayout     = "20060102_150405"
+)
+
+var ErrInvalidDocumentName = errors.New("invalid document name")
+
+var (
+	documentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+	// variantSuffixPattern matches the timestamp that variants append to the
+	// name of their document.
+	variantSuffixPattern = regexp.MustCompile(`_[0-9]{8}_[0-9]{6}$`)
+)
+
+// reservedDocumentNames cannot be created as files on Windows.
+var reservedDocumentNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// DocumentName identifies a document, it is the file name of the original in
+// the adocs folder without the extension. Only names returned by
+// parseDocumentName are turned into paths, so no name can point outside of
+// the adocs and work folders.
+type DocumentName string
+
+// parseDocumentName canonicalizes a name from a request, surrounding spaces
+// and the .adoc extension are dropped, and checks that it is a safe file name.
+// Names ending like a variant are rejected, their variants could not be told
+// apart from the ones of other documents.
+func parseDocumentName(name string) (DocumentName, error) {
+	canonical := strings.TrimSuffix(strings.TrimSpace(name), ".adoc")
+	switch {
+	case canonical == "":
+		return "", fmt.Errorf("%w: name is empty", ErrInvalidDocumentName)
+	case len(canonical) > maxDocumentNameLength:
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidDocumentName, maxDocumentNameLength)
+	case !documentNamePattern.MatchString(canonical):
+		return "", fmt.Errorf("%w: %q may only contain letters, digits, _ and -", ErrInvalidDocumentName, name)
+	case reservedDocumentNames[strings.ToLower(canonical)]:
+		return "", fmt.Errorf("%w: %q is reserved", ErrInvalidDocumentName, name)
+	case variantSuffixPattern.MatchString(canonical):
+		return "", fmt.Errorf("%w: %q ends like a saved version", ErrInvalidDocumentName, name)
+	}
+	return DocumentName(canonical), nil
+}
+
+// originalPath is the path of the document in the adocs folder.
+func (n DocumentName) originalPath() string {
+	return filepath.Join(adocsDirName, string(n)+".adoc")
+}
+
+// workPath is the path of a file that belongs to the document in the work
+// folder, e.g. workPath(".state.json").
+func (n DocumentName) workPath(suffix string) string {
+	return filepath.Join(workDirName, string(n)+suffix)
+}
+
+// variantPath is the path of the variant saved at a time.
+func (n DocumentName) variantPath(t time.Time) string {
+	return n.workPath("_" + t.Format(variantTimeLayout) + ".adoc")
+}
+
+// variantTime returns when a file in the work folder was saved if it is a
+// variant of exactly this document, and not of one whose name only starts
+// the same.
+func (n DocumentName) variantTime(fileName string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(fileName, string(n)+"_")
+	if !ok {
+		return time.Time{}, false
+	}
+	timestamp, ok := strings.CutSuffix(rest, ".adoc")
+	if !ok || len(timestamp) != len(variantTimeLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(variantTimeLayout, timestamp)
+	return t, err == nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// hostileDocumentNames try to leave the adocs and work folders.
+var hostileDocumentNames = []string{
+	"",
+	" ",
+	".",
+	"..",
+	"../invoice",
+	"..%2finvoice",
+	"%2e%2e",
+	"%2e%2e%2finvoice",
+	"/etc/passwd",
+	"/invoice",
+	"C:\\Windows\\win.ini",
+	"..\\invoice",
+	"invoice\\..\\..\\secret",
+	"adocs/invoice",
+	"invoice\x00.adoc",
+	"invoice\x00",
+	".adoc",
+	"..adoc",
+	"../.adoc",
+	"invoice.adoc.adoc",
+	".hidden",
+	"-flag",
+	"con",
+	"LPT1",
+	"invoice_20230629_101010",
+	"invoice_20230629_101010.adoc",
+	strings.Repeat("a", maxDocumentNameLength+1),
+}
+
+func TestParseDocumentNameRejectsHostileNames(t *testing.T) {
+	for _, name := range hostileDocumentNames {
+		if parsed, err := parseDocumentName(name); !errors.Is(err, ErrInvalidDocumentName) {
+			t.Errorf("parseDocumentName(%q) = %q, %v, want ErrInvalidDocumentName", name, parsed, err)
+		}
+	}
+}
+
+func TestParseDocumentNameCanonicalizes(t *testing.T) {
+	tests := map[string]DocumentName{
+		"invoice":        "invoice",
+		"invoice.adoc":   "invoice",
+		" invoice.adoc ": "invoice",
+		"invoice\n":      "invoice",
+		"friend_letter":  "friend_letter",
+		"2023-Q2":        "2023-Q2",
+		"invoice_2023":   "invoice_2023",
+	}
+	for name, want := range tests {
+		if got, err := parseDocumentName(name); err != nil || got != want {
+			t.Errorf("parseDocumentName(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+}
+
+// TestDocumentPathsStayInRoots checks that every path of a valid name is a
+// direct child of its folder.
+func TestDocumentPathsStayInRoots(t *testing.T) {
+	names := []string{"invoice", "a", "A-1_b", strings.Repeat("z", maxDocumentNameLength)}
+	for _, name := range names {
+		parsed, err := parseDocumentName(name)
+		if err != nil {
+			t.Fatalf("parseDocumentName(%q): %v", name, err)
+		}
+		checkDocumentPaths(t, parsed)
+	}
+}
+
+func checkDocumentPaths(t *testing.T, name DocumentName) {
+	t.Helper()
+	paths := map[string]string{
+		name.originalPath():                                                adocsDirName,
+		name.workPath(".state.json"):                                       workDirName,
+		name.workPath(".acl.json"):                                         workDirName,
+		name.variantPath(time.Now()):                                       workDirName,
+		name.variantPath(time.Unix(0, 0)):                                  workDirName,
+		name.variantPath(time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)): workDirName,
+	}
+	for path, root := range paths {
+		if filepath.Dir(path) != root || filepath.Clean(path) != path {
+			t.Errorf("path %q of %q is not a file in %s", path, name, root)
+		}
+		if !strings.HasPrefix(filepath.Base(path), string(name)) {
+			t.Errorf("path %q does not belong to %q", path, name)
+		}
+	}
+
+	variant := filepath.Base(name.variantPath(time.Now()))
+	if _, ok := name.variantTime(variant); !ok {
+		t.Errorf("variantTime(%q) of %q does not recognize its own variant", variant, name)
+	}
+}
+
+// TestCanonicalizeDocument checks that handlers only see canonical names, so
+// "invoice.adoc" shares the budget, numbers and links of "invoice".
+func TestCanonicalizeDocument(t *testing.T) {
+	a := &app{}
+	var seen string
+	router := mux.NewRouter()
+	router.Use(a.canonicalizeDocument)
+	router.HandleFunc("/pdf/{filename}/change", func(w http.ResponseWriter, r *http.Request) {
+		seen = mux.Vars(r)["filename"]
+	})
+	router.HandleFunc("/api/v1/usage", func(w http.ResponseWriter, r *http.Request) {
+		seen = r.URL.Query().Get("document")
+	})
+
+	tests := []struct {
+		target string
+		status int
+		want   string
+	}{
+		{"/pdf/invoice/change", http.StatusOK, "invoice"},
+		{"/pdf/invoice.adoc/change", http.StatusOK, "invoice"},
+		{"/pdf/invoice%00/change", http.StatusBadRequest, ""},
+		{"/pdf/..%5Cinvoice/change", http.StatusBadRequest, ""},
+		{"/pdf/..adoc/change", http.StatusBadRequest, ""},
+		{"/api/v1/usage?document=invoice.adoc", http.StatusOK, "invoice"},
+		{"/api/v1/usage?document=..%2Finvoice", http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		seen = ""
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+		if recorder.Code != test.status || seen != test.want {
+			t.Errorf("%s: status %d, handler saw %q, want %d and %q", test.target, recorder.Code, seen, test.status, test.want)
+		}
+	}
+}
+
+func FuzzParseDocumentName(f *testing.F) {
+	for _, name := range hostileDocumentNames {
+		f.Add(name)
+	}
+	f.Add("invoice")
+	f.Add("invoice.adoc")
+	f.Add(" friend_letter ")
+
+	f.Fuzz(func(t *testing.T, name string) {
+		parsed, err := parseDocumentName(name)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidDocumentName) {
+				t.Fatalf("parseDocumentName(%q) returned %v, want ErrInvalidDocumentName", name, err)
+			}
+			return
+		}
+
+		s := string(parsed)
+		if strings.ContainsAny(s, "/\\\x00.%: \t\r\n") || strings.Contains(s, "..") {
+			t.Fatalf("parseDocumentName(%q) = %q contains a path character", name, s)
+		}
+		if again, err := parseDocumentName(s); err != nil || again != parsed {
+			t.Fatalf("parseDocumentName(%q) = %q, %v is not canonical", s, again, err)
+		}
+		checkDocumentPaths(t, parsed)
+	})
+}
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// ErrInvoiceInconsistent is returned by Reconcile when the totals of an
+// invoice do not match its line items and the service is configured to
+// reject such variants instead of fixing them.
+var ErrInvoiceInconsistent = errors.New("invoice totals do not match the line items")
+
+type InvoiceService interface {
+	Check(ctx context.Context, markup []byte) (InvoiceReport, error)
+	Reconcile(ctx context.Context, markup []byte) ([]byte, InvoiceReport, error)
+}
+
+// InvoiceReport describes the line items and totals found in a document.
+type InvoiceReport struct {
+	weaver.AutoMarshal
+	IsInvoice bool       `json:"isInvoice"`
+	Items     []LineItem `json:"items,omitempty"`
+	Subtotal  string     `json:"subtotal,omitempty"`
+	VAT       string     `json:"vat,omitempty"`
+	Total     string     `json:"total,omitempty"`
+	Issues    []string   `json:"issues,omitempty"`
+}
+
+// LineItem is one row of the position table of an invoice.
+type LineItem struct {
+	weaver.AutoMarshal
+	Position    string `json:"position"`
+	Description string `json:"description"`
+	Quantity    string `json:"quantity,omitempty"`
+	UnitPrice   string `json:"unitPrice,omitempty"`
+	Amount      string `json:"amount"`
+}
+
+type invoiceConfig struct {
+	// OnMismatch is either "fix" (default) to rewrite wrong amounts and
+	// totals, or "reject" to refuse the variant.
+	OnMismatch string `toml:"on_mismatch"`
+}
+
+// Implementation of the InvoiceService component.
+type invoiceService struct {
+	weaver.Implements[InvoiceService]
+	weaver.WithConfig[invoiceConfig]
+}
+
+func (s *invoiceService) Init(context.Context) error {
+	switch s.Config().OnMismatch {
+	case "", "fix", "reject":
+		return nil
+	default:
+		return fmt.Errorf("invalid on_mismatch %q, expected fix or reject", s.Config().OnMismatch)
+	}
+}
+
+func (s *invoiceService) Check(_ context.Context, markup []byte) (InvoiceReport, error) {
+	invoice, ok := parseInvoice(markup)
+	if !ok {
+		return InvoiceReport{}, nil
+	}
+	return invoice.report(), nil
+}
+
+func (s *invoiceService) Reconcile(_ context.Context, markup []byte) ([]byte, InvoiceReport, error) {
+	invoice, ok := parseInvoice(markup)
+	if !ok {
+		return markup, InvoiceReport{}, nil
+	}
+
+	report := invoice.report()
+	if len(report.Issues) == 0 {
+		return markup, report, nil
+	}
+
+	if s.Config().OnMismatch == "reject" {
+		return nil, report, fmt.Errorf("%w: %s", ErrInvoiceInconsistent, strings.Join(report.Issues, "; "))
+	}
+
+	s.Logger().Info("Fixing invoice totals", "issues", report.Issues)
+	return invoice.fix(), report, nil
+}
+
+var (
+	amountPattern      = regexp.MustCompile(`^\s*(-?[0-9][0-9.,']*)\s*(.*?)\s*$`)
+	summaryLinePattern = regexp.MustCompile(`(?i)^(\.?\*?)(zwischensumme|nettobetrag|subtotal|mwst\.?|ust\.?|umsatzsteuer|vat|gesamtsumme|gesamtbetrag|total)\b([^:]*):\s*(-?[0-9][0-9.,']*)\s*(.*?)\s*$`)
+	vatRatePattern     = regexp.MustCompile(`([0-9]+(?:[.,][0-9]+)?)\s*%`)
+	// thousandsPattern matches numbers like 1.000 or 12.345.678, where the
+	// dots group thousands as in de-DE.
+	thousandsPattern = regexp.MustCompile(`^-?[1-9][0-9]{0,2}(\.[0-9]{3})+$`)
+)
+
+const (
+	summarySubtotal = "subtotal"
+	summaryVAT      = "vat"
+	summaryTotal    = "total"
+)
+
+// invoice is the parsed form of a document that contains a position table
+// with an amount column.
+type invoice struct {
+	lines   []string
+	rows    []invoiceRow
+	summary []summaryLine
+	vatRate *big.Rat
+	format  moneyFormat
+}
+
+type invoiceRow struct {
+	cells       []tableCell
+	position    int
+	description int
+	quantity    int
+	unitPrice   int
+	amount      int
+}
+
+// tableCell remembers where a cell was found so that it can be rewritten.
+type tableCell struct {
+	line  int
+	index int
+	text  string
+}
+
+type summaryLine struct {
+	line   int
+	kind   string
+	prefix string
+	label  string
+	amount *big.Rat
+}
+
+// moneyFormat remembers how amounts are written in a document so that fixed
+// values look like the ones the author typed.
+type moneyFormat struct {
+	decimalComma bool
+	currency     string
+}
+
+func parseInvoice(markup []byte) (*invoice, bool) {
+	lines := strings.Split(string(markup), "\n")
+	inv := &invoice{lines: lines}
+
+	start, end := findHeaderTable(lines)
+	if start == -1 {
+		return nil, false
+	}
+
+	cells := parseTableCells(lines, start+1, end)
+	if len(cells) == 0 {
+		return nil, false
+	}
+
+	header := cells[0]
+	columns := len(header)
+	amount, quantity, unitPrice := -1, -1, -1
+	for i, cell := range header {
+		switch normalizeHeader(cell.text) {
+		case "betrag", "amount", "summe", "gesamt", "gesamtpreis", "total", "preis", "price":
+			amount = i
+		case "menge", "anzahl", "quantity", "qty":
+			quantity = i
+		case "einzelpreis", "unitprice", "stückpreis":
+			unitPrice = i
+		}
+	}
+	if amount == -1 {
+		return nil, false
+	}
+
+	var flat []tableCell
+	for _, row := range cells[1:] {
+		flat = append(flat, row...)
+	}
+	for i := 0; i+columns <= len(flat); i += columns {
+		inv.rows = append(inv.rows, invoiceRow{
+			cells:       flat[i : i+columns],
+			position:    0,
+			description: minInt(1, columns-1),
+			quantity:    quantity,
+			unitPrice:   unitPrice,
+			amount:      amount,
+		})
+	}
+	if len(inv.rows) == 0 {
+		return nil, false
+	}
+
+	for _, row := range inv.rows {
+		if _, format, ok := parseMoney(row.cells[row.amount].text); ok {
+			inv.format = format
+			break
+		}
+	}
+
+	for _, attribute := range parseHeaderAttributes(markup) {
+		if attribute.Name == "vat_rate" {
+			if rate, ok := parseDecimal(strings.TrimSuffix(strings.TrimSpace(attribute.Value), "%")); ok {
+				inv.vatRate = rate
+			}
+		}
+	}
+
+	for i := end + 1; i < len(lines); i++ {
+		match := summaryLinePattern.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+		if match == nil {
+			continue
+		}
+		value, _, ok := parseMoney(match[4] + " " + match[5])
+		if !ok {
+			continue
+		}
+
+		line := summaryLine{line: i, prefix: match[1], label: match[2] + match[3], amount: value}
+		switch strings.ToLower(strings.TrimSuffix(match[2], ".")) {
+		case "zwischensumme", "nettobetrag", "subtotal":
+			line.kind = summarySubtotal
+		case "mwst", "ust", "umsatzsteuer", "vat":
+			line.kind = summaryVAT
+			if inv.vatRate == nil {
+				if rate := vatRatePattern.FindStringSubmatch(match[3]); rate != nil {
+					inv.vatRate, _ = parseDecimal(rate[1])
+				}
+			}
+		default:
+			line.kind = summaryTotal
+		}
+		inv.summary = append(inv.summary, line)
+	}
+
+	return inv, true
+}
+
+func (inv *invoice) report() InvoiceReport {
+	report := InvoiceReport{IsInvoice: true}
+
+	subtotal := new(big.Rat)
+	for _, row := range inv.rows {
+		item := LineItem{
+			Position:    row.cells[row.position].text,
+			Description: row.cells[row.description].text,
+			Amount:      row.cells[row.amount].text,
+		}
+
+		amount, ok := row.expectedAmount()
+		if !ok {
+			report.Issues = append(report.Issues, fmt.Sprintf("position %s: cannot read amount %q", item.Position, item.Amount))
+			report.Items = append(report.Items, item)
+			continue
+		}
+		if row.quantity != -1 {
+			item.Quantity = row.cells[row.quantity].text
+		}
+		if row.unitPrice != -1 {
+			item.UnitPrice = row.cells[row.unitPrice].text
+		}
+		if written, _, ok := parseMoney(item.Amount); ok && written.Cmp(amount) != 0 {
+			report.Issues = append(report.Issues, fmt.Sprintf("position %s: amount is %s, expected %s", item.Position, item.Amount, inv.format.format(amount)))
+		}
+
+		report.Items = append(report.Items, item)
+		subtotal.Add(subtotal, amount)
+	}
+
+	report.Subtotal = inv.format.format(subtotal)
+	expected := map[string]*big.Rat{summarySubtotal: subtotal}
+	if inv.vatRateKnown() {
+		vat, total := inv.totals(subtotal)
+		report.VAT = inv.format.format(vat)
+		report.Total = inv.format.format(total)
+		expected[summaryVAT], expected[summaryTotal] = vat, total
+	} else {
+		report.Issues = append(report.Issues, "VAT rate is unknown: name it in the VAT line or set :vat_rate:")
+	}
+
+	for _, line := range inv.summary {
+		if expected[line.kind] != nil && line.amount.Cmp(expected[line.kind]) != 0 {
+			report.Issues = append(report.Issues, fmt.Sprintf("%s is %s, expected %s", strings.TrimSpace(line.label), inv.format.format(line.amount), inv.format.format(expected[line.kind])))
+		}
+	}
+
+	return report
+}
+
+// fix returns the markup with every line item amount and every summary line
+// replaced by the computed value. VAT and total lines stay as they are while
+// the VAT rate is unknown.
+func (inv *invoice) fix() []byte {
+	lines := append([]string(nil), inv.lines...)
+
+	subtotal := new(big.Rat)
+	for _, row := range inv.rows {
+		amount, ok := row.expectedAmount()
+		if !ok {
+			continue
+		}
+		subtotal.Add(subtotal, amount)
+
+		if row.quantity != -1 && row.unitPrice != -1 {
+			cell := row.cells[row.amount]
+			lines[cell.line] = replaceCell(lines[cell.line], cell.index, inv.format.format(amount))
+		}
+	}
+
+	expected := map[string]*big.Rat{summarySubtotal: subtotal}
+	if inv.vatRateKnown() {
+		expected[summaryVAT], expected[summaryTotal] = inv.totals(subtotal)
+	}
+	for _, line := range inv.summary {
+		if expected[line.kind] != nil {
+			lines[line.line] = fmt.Sprintf("%s%s: %s", line.prefix, line.label, inv.format.format(expected[line.kind]))
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// vatRateKnown tells whether VAT can be computed. An invoice without a VAT
+// line charges none, one with a VAT line needs the rate in its label or in
+// the :vat_rate: attribute.
+func (inv *invoice) vatRateKnown() bool {
+	if inv.vatRate != nil {
+		return true
+	}
+	for _, line := range inv.summary {
+		if line.kind == summaryVAT {
+			return false
+		}
+	}
+	return true
+}
+
+func (inv *invoice) totals(subtotal *big.Rat) (*big.Rat, *big.Rat) {
+	vat := new(big.Rat)
+	if inv.vatRate != nil {
+		vat.Mul(subtotal, inv.vatRate)
+		vat.Quo(vat, big.NewRat(100, 1))
+		vat = roundCents(vat)
+	}
+	return vat, new(big.Rat).Add(subtotal, vat)
+}
+
+// expectedAmount returns quantity * unit price when both columns exist and
+// the written amount otherwise.
+func (row invoiceRow) expectedAmount() (*big.Rat, bool) {
+	if row.quantity != -1 && row.unitPrice != -1 {
+		quantity, ok := parseDecimal(row.cells[row.quantity].text)
+		if !ok {
+			return nil, false
+		}
+		unitPrice, _, ok := parseMoney(row.cells[row.unitPrice].text)
+		if !ok {
+			return nil, false
+		}
+		return roundCents(new(big.Rat).Mul(quantity, unitPrice)), true
+	}
+
+	amount, _, ok := parseMoney(row.cells[row.amount].text)
+	return amount, ok
+}
+
+// findHeaderTable returns the line indexes of the opening and closing
+// delimiters of the first table that declares a header row.
+func findHeaderTable(lines []string) (int, int) {
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "|===" || !strings.Contains(lines[i-1], `options="header"`) {
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "|===" {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// parseTableCells returns the cells of each non-empty line between start and
+// end. The first entry is the header row.
+func parseTableCells(lines []string, start int, end int) [][]tableCell {
+	var rows [][]tableCell
+	for i := start; i < end; i++ {
+		var row []tableCell
+		for index, text := range splitCells(lines[i]) {
+			row = append(row, tableCell{line: i, index: index, text: text})
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// splitCells splits a table line at unescaped `|` characters.
+func splitCells(line string) []string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "|") {
+		return nil
+	}
+
+	var cells []string
+	var current strings.Builder
+	for i := 1; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			current.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(current.String()))
+}
+
+func replaceCell(line string, index int, text string) string {
+	cells := splitCells(line)
+	if index >= len(cells) {
+		return line
+	}
+	cells[index] = text
+	return formatTableRow(cells)
+}
+
+func normalizeHeader(text string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(text, " ", ""), "-", ""))
+}
+
+// parseMoney reads amounts like "1.234,50 EUR" or "1,234.50 USD". A dot
+// followed by exactly three digits separates thousands, so "1.000 EUR" is a
+// thousand euros.
+func parseMoney(text string) (*big.Rat, moneyFormat, bool) {
+	match := amountPattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil, moneyFormat{}, false
+	}
+
+	number := strings.ReplaceAll(match[1], "'", "")
+	format := moneyFormat{currency: match[2]}
+	lastComma := strings.LastIndex(number, ",")
+	lastDot := strings.LastIndex(number, ".")
+	switch {
+	case lastComma > lastDot:
+		format.decimalComma = true
+		number = strings.ReplaceAll(number, ".", "")
+		number = strings.Replace(number, ",", ".", 1)
+	case thousandsPattern.MatchString(number):
+		format.decimalComma = true
+		number = strings.ReplaceAll(number, ".", "")
+	case lastDot > lastComma:
+		number = strings.ReplaceAll(number, ",", "")
+	}
+
+	value, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return nil, moneyFormat{}, false
+	}
+	return value, format, true
+}
+
+func parseDecimal(text string) (*big.Rat, bool) {
+	value, _, ok := parseMoney(text)
+	return value, ok
+}
+
+func roundCents(value *big.Rat) *big.Rat {
+	cents := new(big.Rat).Mul(value, big.NewRat(100, 1))
+	num := new(big.Int).Set(cents.Num())
+	den := cents.Denom()
+
+	// Round half away from zero.
+	negative := num.Sign() < 0
+	num.Abs(num)
+	num.Mul(num, big.NewInt(2))
+	num.Add(num, den)
+	num.Quo(num, new(big.Int).Mul(den, big.NewInt(2)))
+	if negative {
+		num.Neg(num)
+	}
+	return new(big.Rat).SetFrac(num, big.NewInt(100))
+}
+
+func (f moneyFormat) format(value *big.Rat) string {
+	text := value.FloatString(2)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	whole, fraction := text[:len(text)-3], text[len(text)-2:]
+	thousands, decimal := ",", "."
+	if f.decimalComma {
+		thousands, decimal = ".", ","
+	}
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	result := grouped.String() + decimal + fraction
+	if negative {
+		result = "-" + result
+	}
+	if f.currency != "" {
+		result += " " + f.currency
+	}
+	return result
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
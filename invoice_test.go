@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := map[string]string{
+		"1.000 EUR":     "1000",
+		"12.345.678":    "12345678",
+		"1.234,50 EUR":  "1234.5",
+		"1,234.50 USD":  "1234.5",
+		"1.50 EUR":      "1.5",
+		"0.125":         "0.125",
+		"19,5":          "19.5",
+		"-1.000,00 EUR": "-1000",
+		"225,00 EUR":    "225",
+		"1'000.00 CHF":  "1000",
+	}
+	for text, want := range tests {
+		got, _, ok := parseMoney(text)
+		if !ok || got.Cmp(mustRat(t, want)) != 0 {
+			t.Errorf("parseMoney(%q) = %v, %v, want %s", text, got, ok, want)
+		}
+	}
+}
+
+func mustRat(t *testing.T, text string) *big.Rat {
+	t.Helper()
+	value, ok := new(big.Rat).SetString(text)
+	if !ok {
+		t.Fatalf("invalid number %q", text)
+	}
+	return value
+}
+
+const invoiceWithoutRate = `= Rechnung
+
+[options="header"]
+|===
+|Pos |Beschreibung |Betrag
+|1 |Beratung |1.000 EUR
+|===
+
+.Zwischensumme: 900,00 EUR
+.MwSt.: 123,45 EUR
+.Gesamtsumme: 1.023,45 EUR
+`
+
+func TestInvoiceUnknownVATRate(t *testing.T) {
+	inv, ok := parseInvoice([]byte(invoiceWithoutRate))
+	if !ok {
+		t.Fatal("not parsed as an invoice")
+	}
+
+	report := inv.report()
+	if report.Subtotal != "1.000,00 EUR" {
+		t.Errorf("subtotal is %s, want 1.000,00 EUR", report.Subtotal)
+	}
+	if report.VAT != "" || report.Total != "" {
+		t.Errorf("got VAT %q and total %q without a rate", report.VAT, report.Total)
+	}
+	if !strings.Contains(strings.Join(report.Issues, "\n"), "VAT rate is unknown") {
+		t.Errorf("issues %q do not mention the unknown rate", report.Issues)
+	}
+
+	fixed := string(inv.fix())
+	for _, line := range []string{".Zwischensumme: 1.000,00 EUR", ".MwSt.: 123,45 EUR", ".Gesamtsumme: 1.023,45 EUR"} {
+		if !strings.Contains(fixed, line) {
+			t.Errorf("fixed invoice lacks %q:\n%s", line, fixed)
+		}
+	}
+
+	// With the rate the totals are fixed as well
+	inv, _ = parseInvoice([]byte(strings.Replace(invoiceWithoutRate, "= Rechnung\n", "= Rechnung\n:vat_rate: 19\n", 1)))
+	fixed = string(inv.fix())
+	for _, line := range []string{".MwSt.: 190,00 EUR", ".Gesamtsumme: 1.190,00 EUR"} {
+		if !strings.Contains(fixed, line) {
+			t.Errorf("fixed invoice lacks %q:\n%s", line, fixed)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	lockMarker   = "// sudocu:lock"
+	unlockMarker = "// sudocu:unlock"
+	// lockAttribute lists header attributes that must not change, e.g.
+	// ":sudocu-lock: vat_number, iban". It is always locked itself.
+	lockAttribute = "sudocu-lock"
+)
+
+// ErrLockedContent is returned when GPT changed a locked region or attribute
+// and on_locked_change is "reject", or when it cannot be restored.
+var ErrLockedContent = errors.New("change would alter locked content")
+
+// lockedRegion is a block between a lock and an unlock marker, both included.
+// A lock without unlock extends to the next lock marker or the end of the
+// document.
+type lockedRegion struct {
+	start, end int
+	// closed is set if the region ends with an unlock marker.
+	closed bool
+	// anchor is the last non-empty line before the region, where it is put
+	// back if GPT dropped it.
+	anchor string
+}
+
+func findLockedRegions(lines []string) []lockedRegion {
+	var regions []lockedRegion
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != lockMarker {
+			continue
+		}
+		region := lockedRegion{start: i, end: len(lines) - 1}
+		for j := i - 1; j >= 0; j-- {
+			if strings.TrimSpace(lines[j]) != "" {
+				region.anchor = lines[j]
+				break
+			}
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == lockMarker {
+				region.end = j - 1
+				break
+			}
+			if strings.TrimSpace(lines[j]) == unlockMarker {
+				region.end, region.closed = j, true
+				break
+			}
+		}
+		regions = append(regions, region)
+		i = region.end
+	}
+	return regions
+}
+
+func (r lockedRegion) text(lines []string) string {
+	return strings.Join(lines[r.start:r.end+1], "\n")
+}
+
+// enforceLocks checks that the locked regions and attributes of the old
+// markup are byte-identical in the new markup. Changed content is put back
+// if restore is set, otherwise the change is rejected.
+func enforceLocks(oldMarkup []byte, newMarkup []byte, restore bool) ([]byte, []string, error) {
+	oldLines := strings.Split(string(oldMarkup), "\n")
+	newLines := strings.Split(string(newMarkup), "\n")
+	oldRegions := findLockedRegions(oldLines)
+	newRegions := findLockedRegions(newLines)
+
+	var violations []string
+	for i, region := range oldRegions {
+		if i >= len(newRegions) || newRegions[i].text(newLines) != region.text(oldLines) {
+			violations = append(violations, fmt.Sprintf("locked region at line %d", region.start+1))
+		}
+	}
+	if len(newRegions) > len(oldRegions) {
+		violations = append(violations, "new locked regions")
+	}
+
+	lockedValues := lockedAttributes(oldMarkup)
+	newAttributes := make(map[string]string)
+	for _, attribute := range parseHeaderAttributes(newMarkup) {
+		newAttributes[attribute.Name] = attribute.Value
+	}
+	for _, name := range sortedKeys(lockedValues) {
+		if value, ok := newAttributes[name]; !ok || value != lockedValues[name] {
+			violations = append(violations, "locked attribute "+name)
+		}
+	}
+
+	if len(violations) == 0 {
+		return newMarkup, nil, nil
+	}
+	// Markers GPT added on its own would lock text nobody asked to lock
+	if !restore || len(newRegions) > len(oldRegions) {
+		return nil, violations, fmt.Errorf("%w: %s", ErrLockedContent, strings.Join(violations, ", "))
+	}
+
+	restored, err := restoreRegions(oldLines, oldRegions, newLines, newRegions)
+	if err != nil {
+		return nil, violations, err
+	}
+	return setHeaderAttributes([]byte(strings.Join(restored, "\n")), lockedValues), violations, nil
+}
+
+// restoreRegions puts the old regions back. Regions are replaced in place
+// while GPT kept all markers, otherwise the remains of the regions are dropped
+// and each one goes back after its anchor.
+func restoreRegions(oldLines []string, oldRegions []lockedRegion, newLines []string, newRegions []lockedRegion) ([]string, error) {
+	if sameMarkers(oldRegions, newRegions) {
+		var result []string
+		next := 0
+		for i, region := range newRegions {
+			result = append(result, newLines[next:region.start]...)
+			result = append(result, oldLines[oldRegions[i].start:oldRegions[i].end+1]...)
+			next = region.end + 1
+		}
+		return append(result, newLines[next:]...), nil
+	}
+
+	// Drop what is left of the regions, but remember where they were
+	const hole = "\x00"
+	var result []string
+	next := 0
+	for _, region := range regionRemains(oldLines, oldRegions, newLines, newRegions) {
+		result = append(result, newLines[next:region.start]...)
+		result = append(result, hole)
+		next = region.end + 1
+	}
+	result = append(result, newLines[next:]...)
+
+	// Each old region goes back after its anchor, or where a region was left
+	position := 0
+	for _, region := range oldRegions {
+		block := append([]string{}, oldLines[region.start:region.end+1]...)
+
+		insertAt, replace := -1, 0
+		if region.anchor == "" {
+			insertAt = 0
+		}
+		for i := position; i < len(result) && insertAt == -1; i++ {
+			if result[i] == region.anchor {
+				insertAt = i + 1
+			}
+		}
+		for i := position; i < len(result) && insertAt == -1; i++ {
+			if result[i] == hole {
+				insertAt, replace = i, 1
+			}
+		}
+		if insertAt == -1 {
+			return nil, fmt.Errorf("%w: locked region at line %d was removed and cannot be put back", ErrLockedContent, region.start+1)
+		}
+
+		result = append(result[:insertAt], append(block, result[insertAt+replace:]...)...)
+		position = insertAt + len(block)
+	}
+
+	var restored []string
+	for _, line := range result {
+		if line != hole {
+			restored = append(restored, line)
+		}
+	}
+	return restored, nil
+}
+
+// sameMarkers reports whether GPT kept every lock and unlock marker, so the
+// new regions can be replaced one by one. A region that lost its unlock
+// marker runs to the next region or the end of the document and would take
+// the text after it along.
+func sameMarkers(oldRegions []lockedRegion, newRegions []lockedRegion) bool {
+	if len(oldRegions) != len(newRegions) {
+		return false
+	}
+	for i := range newRegions {
+		if newRegions[i].closed != oldRegions[i].closed {
+			return false
+		}
+	}
+	return true
+}
+
+// regionRemains returns the parts of the new markup that are left of locked
+// regions, in order. A region without its unlock marker only covers the
+// lines it shares with the start of an old region, and an unlock marker
+// without its lock covers the lines it shares with the end of one. The text
+// GPT wrote after or before them is kept.
+func regionRemains(oldLines []string, oldRegions []lockedRegion, newLines []string, newRegions []lockedRegion) []lockedRegion {
+	var remains []lockedRegion
+	next := 0
+	add := func(start, end int) {
+		// Stray unlock markers before the region
+		for i := next; i < start; i++ {
+			if strings.TrimSpace(newLines[i]) != unlockMarker {
+				continue
+			}
+			length := 1
+			for _, old := range oldRegions {
+				block := oldLines[old.start : old.end+1]
+				n := 0
+				for n < len(block) && i-n >= next && newLines[i-n] == block[len(block)-1-n] {
+					n++
+				}
+				if n > length {
+					length = n
+				}
+			}
+			remains = append(remains, lockedRegion{start: i - length + 1, end: i})
+		}
+		if start < len(newLines) {
+			remains = append(remains, lockedRegion{start: start, end: end})
+		}
+		next = end + 1
+	}
+
+	for _, region := range newRegions {
+		end := region.end
+		if !region.closed {
+			end = region.start
+			for _, old := range oldRegions {
+				block := oldLines[old.start : old.end+1]
+				n := 0
+				for n < len(block) && region.start+n < len(newLines) && newLines[region.start+n] == block[n] {
+					n++
+				}
+				if region.start+n-1 > end {
+					end = region.start + n - 1
+				}
+			}
+		}
+		add(region.start, end)
+	}
+	add(len(newLines), len(newLines)-1)
+	return remains
+}
+
+// lockedAttributes returns the values of the attributes listed in the
+// sudocu-lock attribute of a document, and of sudocu-lock itself.
+func lockedAttributes(markup []byte) map[string]string {
+	values := make(map[string]string)
+	for _, attribute := range parseHeaderAttributes(markup) {
+		values[attribute.Name] = attribute.Value
+	}
+	locks, ok := values[lockAttribute]
+	if !ok {
+		return nil
+	}
+
+	locked := map[string]string{lockAttribute: locks}
+	for _, name := range strings.FieldsFunc(locks, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if value, ok := values[name]; ok {
+			locked[name] = value
+		}
+	}
+	return locked
+}
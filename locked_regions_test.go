@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+const lockedDocument = `= Rechnung
+:sudocu-lock: iban
+:iban: DE00 1234
+
+Intro
+
+// sudocu:lock
+IBAN DE00 1234
+// sudocu:unlock
+
+end
+`
+
+func TestEnforceLocks(t *testing.T) {
+	tests := []struct {
+		name      string
+		newMarkup string
+		restored  string
+		violated  bool
+	}{{
+		name:      "unchanged region",
+		newMarkup: "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nNew intro\n\n// sudocu:lock\nIBAN DE00 1234\n// sudocu:unlock\n\nend\n",
+		restored:  "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nNew intro\n\n// sudocu:lock\nIBAN DE00 1234\n// sudocu:unlock\n\nend\n",
+	}, {
+		name:      "region text edited",
+		newMarkup: "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nIntro\n\n// sudocu:lock\nIBAN DE99 9999\n// sudocu:unlock\n\nnew end\n",
+		restored:  "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nIntro\n\n// sudocu:lock\nIBAN DE00 1234\n// sudocu:unlock\n\nnew end\n",
+		violated:  true,
+	}, {
+		name:      "region dropped entirely",
+		newMarkup: "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nIntro\n\nnew end\n",
+		restored:  "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nIntro\n// sudocu:lock\nIBAN DE00 1234\n// sudocu:unlock\n\nnew end\n",
+		violated:  true,
+	}, {
+		name:      "unlock marker dropped",
+		newMarkup: "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nIntro\n\n// sudocu:lock\nIBAN DE00 1234\n\nnew end\n",
+		restored:  "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nIntro\n// sudocu:lock\nIBAN DE00 1234\n// sudocu:unlock\n\n\nnew end\n",
+		violated:  true,
+	}, {
+		name:      "lock marker dropped",
+		newMarkup: "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nIntro\n\nIBAN DE00 1234\n// sudocu:unlock\n\nnew end\n",
+		restored:  "= Rechnung\n:sudocu-lock: iban\n:iban: DE00 1234\n\nIntro\n// sudocu:lock\nIBAN DE00 1234\n// sudocu:unlock\n\n\nnew end\n",
+		violated:  true,
+	}, {
+		name:      "locked attribute changed",
+		newMarkup: "= Rechnung\n:sudocu-lock: iban\n:iban: DE99\n\nIntro\n\n// sudocu:lock\nIBAN DE00 1234\n// sudocu:unlock\n\nend\n",
+		restored:  lockedDocument,
+		violated:  true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, violations, err := enforceLocks([]byte(lockedDocument), []byte(test.newMarkup), true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.restored {
+				t.Errorf("restored markup is\n%q\nwant\n%q", got, test.restored)
+			}
+			if (len(violations) > 0) != test.violated {
+				t.Errorf("violations %v, want some: %v", violations, test.violated)
+			}
+
+			_, _, err = enforceLocks([]byte(lockedDocument), []byte(test.newMarkup), false)
+			if test.violated && !errors.Is(err, ErrLockedContent) {
+				t.Errorf("reject mode returned %v, want ErrLockedContent", err)
+			} else if !test.violated && err != nil {
+				t.Errorf("reject mode returned %v", err)
+			}
+		})
+	}
+}
+
+func TestEnforceLocksKeepsTextAfterTwoRegions(t *testing.T) {
+	old := "A\n// sudocu:lock\none\n// sudocu:unlock\nB\n// sudocu:lock\ntwo\n// sudocu:unlock\nC\n"
+	// GPT dropped the first unlock marker and changed the text around
+	changed := "A2\n// sudocu:lock\none\nB2\n// sudocu:lock\ntwo\n// sudocu:unlock\nC2\n"
+
+	got, _, err := enforceLocks([]byte(old), []byte(changed), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "A2\n// sudocu:lock\none\n// sudocu:unlock\nB2\n// sudocu:lock\ntwo\n// sudocu:unlock\nC2\n"
+	if string(got) != want {
+		t.Errorf("restored markup is\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestEnforceLocksRejectsNewRegions(t *testing.T) {
+	changed := lockedDocument + "\n// sudocu:lock\nmine\n// sudocu:unlock\n"
+	if _, _, err := enforceLocks([]byte(lockedDocument), []byte(changed), true); !errors.Is(err, ErrLockedContent) {
+		t.Errorf("got %v, want ErrLockedContent", err)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	sessionCookieName = "sudocu_session"
+	oidcCookieName    = "sudocu_oidc"
+)
+
+// publicPaths can be reached without logging in.
+var publicPaths = map[string]bool{
+	"/login":         true,
+	"/logout":        true,
+	"/auth/oidc":     true,
+	"/auth/callback": true,
+}
+
+type userContextKey struct{}
+
+// requestUser returns the user that sent a request. It is empty when
+// authentication is disabled.
+func requestUser(r *http.Request) User {
+	user, _ := r.Context().Value(userContextKey{}).(User)
+	return user
+}
+
+// authenticate is the middleware that lets only requests with a valid API key
+// or session through. Browsers are sent to the login page instead.
+func (a *app) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Share links carry their own signed token
+		if publicPaths[r.URL.Path] || strings.HasPrefix(r.URL.Path, "/s/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		credentials := Credentials{APIKey: requestAPIKey(r)}
+		if cookie, err := r.Cookie(sessionCookieName); err == nil {
+			credentials.Session = cookie.Value
+		}
+
+		user, err := a.authenticator.Get().Authenticate(r.Context(), credentials)
+		if errors.Is(err, ErrUnauthenticated) {
+			switch {
+			case strings.HasPrefix(r.URL.Path, apiPrefix+"/"):
+				a.writeAPIError(w, http.StatusUnauthorized, "unauthenticated", err.Error())
+			case r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html"):
+				http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			default:
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			}
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			a.Logger().Warn(err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey{}, user)))
+	})
+}
+
+// requestAPIKey reads the key of scripts from "Authorization: Bearer <key>"
+// or the X-API-Key header.
+func requestAPIKey(r *http.Request) string {
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(key)
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+func (a *app) registerLogin(router *mux.Router) {
+	router.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		next := safeRedirect(r.FormValue("next"))
+
+		if r.Method == http.MethodPost {
+			user, err := a.authenticator.Get().Authenticate(r.Context(), Credentials{APIKey: r.FormValue("key")})
+			if err == nil && user.Name != "" {
+				if err := a.startSession(w, r, user); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					a.Logger().Warn(err.Error())
+					return
+				}
+				http.Redirect(w, r, next, http.StatusSeeOther)
+				return
+			}
+			if err != nil && !errors.Is(err, ErrUnauthenticated) {
+				a.Logger().Warn(err.Error())
+			}
+			a.renderLogin(w, r, http.StatusUnauthorized, next, "Unknown API key")
+			return
+		}
+
+		a.renderLogin(w, r, http.StatusOK, next, "")
+	})
+
+	// Logging out only deletes the cookie, the stateless session itself stays
+	// valid until it expires
+	router.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: sessionCookieName, Value: "", Path: "/", MaxAge: -1, HttpOnly: true})
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+	})
+
+	router.HandleFunc("/auth/oidc", func(w http.ResponseWriter, r *http.Request) {
+		state, err := randomToken(16)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		nonce, err := randomToken(16)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		loginURL, err := a.authenticator.Get().OIDCLoginURL(r.Context(), state, nonce)
+		if errors.Is(err, ErrOIDCDisabled) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			a.Logger().Warn(err.Error())
+			return
+		}
+
+		// The state and nonce are compared when the provider redirects back
+		value := strings.Join([]string{state, nonce, url.QueryEscape(safeRedirect(r.FormValue("next")))}, "|")
+		http.SetCookie(w, &http.Cookie{
+			Name:     oidcCookieName,
+			Value:    value,
+			Path:     "/auth/callback",
+			MaxAge:   int((10 * time.Minute).Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, loginURL, http.StatusFound)
+	})
+
+	router.HandleFunc("/auth/callback", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(oidcCookieName)
+		if err != nil {
+			http.Error(w, "Login expired, please try again", http.StatusBadRequest)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: oidcCookieName, Value: "", Path: "/auth/callback", MaxAge: -1, HttpOnly: true})
+
+		parts := strings.SplitN(cookie.Value, "|", 3)
+		if len(parts) != 3 || r.FormValue("state") != parts[0] {
+			http.Error(w, "Invalid login state", http.StatusBadRequest)
+			return
+		}
+		if reason := r.FormValue("error"); reason != "" {
+			http.Error(w, "Login failed: "+reason, http.StatusUnauthorized)
+			return
+		}
+
+		user, err := a.authenticator.Get().OIDCExchange(r.Context(), r.FormValue("code"), parts[1])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			a.Logger().Warn(err.Error())
+			return
+		}
+		if err := a.startSession(w, r, user); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			a.Logger().Warn(err.Error())
+			return
+		}
+
+		next, _ := url.QueryUnescape(parts[2])
+		http.Redirect(w, r, safeRedirect(next), http.StatusSeeOther)
+	})
+}
+
+func (a *app) startSession(w http.ResponseWriter, r *http.Request, user User) error {
+	token, err := a.authenticator.Get().IssueSession(r.Context(), user)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	a.Logger().Info("User logged in", "user", user.Name, "method", user.Method)
+	return nil
+}
+
+func (a *app) renderLogin(w http.ResponseWriter, r *http.Request, status int, next string, message string) {
+	oidcEnabled, err := a.authenticator.Get().OIDCEnabled(r.Context())
+	if err != nil {
+		a.Logger().Warn(err.Error())
+	}
+
+	tmpl, err := template.ParseFiles("login.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Next":  next,
+		"OIDC":  oidcEnabled,
+		"Error": message,
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+	if err := tmpl.Execute(w, data); err != nil {
+		a.Logger().Warn("Error writing response:", err)
+	}
+}
+
+// safeRedirect only allows redirects to paths of this server.
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/list"
+	}
+	return next
+}
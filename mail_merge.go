@@ -0,0 +1,271 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+const outputDirName = "output"
+
+// itemsField is the CSV column / JSON key that holds the line items of a row.
+const itemsField = "items"
+
+type MailMerger interface {
+	MergeToZip(ctx context.Context, fileName string, rows []MergeRow) ([]byte, error)
+	MergeToFolder(ctx context.Context, fileName string, rows []MergeRow) ([]string, error)
+}
+
+// MergeRow holds the data that is substituted into one copy of a template.
+type MergeRow struct {
+	weaver.AutoMarshal
+	Attributes map[string]string
+	Items      [][]string
+}
+
+// Implementation of the MailMerger component.
+type mailMerger struct {
+	weaver.Implements[MailMerger]
+	aDocRepository  weaver.Ref[ADocRepository]
+	pdfGenerator    weaver.Ref[PDFGenerator]
+	invoiceService  weaver.Ref[InvoiceService]
+	sequenceService weaver.Ref[SequenceService]
+}
+
+type mergedDocument struct {
+	name string
+	pdf  []byte
+	// reservation holds the sequence numbers of the copy until it was
+	// written.
+	reservation string
+}
+
+func (m *mailMerger) MergeToZip(ctx context.Context, fileName string, rows []MergeRow) ([]byte, error) {
+	documents, err := m.render(ctx, fileName, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	err = writeZip(archive, documents)
+	if err == nil {
+		err = archive.Close()
+	}
+	if err := m.settleNumbers(ctx, documents, err); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeZip(archive *zip.Writer, documents []mergedDocument) error {
+	for _, document := range documents {
+		w, err := archive.Create(document.name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(document.pdf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mailMerger) MergeToFolder(ctx context.Context, fileName string, rows []MergeRow) ([]string, error) {
+	name, err := parseDocumentName(fileName)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := m.render(ctx, fileName, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Format("20060102_150405")
+	dir := filepath.Join(outputDirName, fmt.Sprintf("%s_%s", name, timestamp))
+	paths, err := writeFolder(dir, documents)
+	if err := m.settleNumbers(ctx, documents, err); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+func writeFolder(dir string, documents []mergedDocument) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, document := range documents {
+		path := filepath.Join(dir, document.name)
+		if err := ioutil.WriteFile(path, document.pdf, 0644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// settleNumbers commits the numbers of the merged copies once they were
+// written, or releases all of them when the merge failed.
+func (m *mailMerger) settleNumbers(ctx context.Context, documents []mergedDocument, err error) error {
+	for _, document := range documents {
+		if err != nil {
+			if releaseErr := m.sequenceService.Get().Release(ctx, document.reservation); releaseErr != nil {
+				m.Logger().Warn("Failed to release sequence numbers", "document", document.name, "err", releaseErr)
+			}
+		} else if commitErr := m.sequenceService.Get().Commit(ctx, document.reservation); commitErr != nil {
+			return commitErr
+		}
+	}
+	return err
+}
+
+func (m *mailMerger) render(ctx context.Context, fileName string, rows []MergeRow) ([]mergedDocument, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no rows to merge")
+	}
+
+	template, err := m.aDocRepository.Get().ReadFile(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Format("20060102_150405")
+
+	var documents []mergedDocument
+	for i, row := range rows {
+		name := fmt.Sprintf("%s_%03d", fileName, i+1)
+
+		// Every merged copy is a document of its own and gets its own number.
+		markup, reservation, err := m.sequenceService.Get().FillPlaceholders(ctx, name+"_"+timestamp, mergeRow(template, row), true)
+		documents = append(documents, mergedDocument{name: name + ".pdf", reservation: reservation})
+		if err != nil {
+			return nil, m.settleNumbers(ctx, documents, fmt.Errorf("row %d: %w", i+1, err))
+		}
+
+		markup, _, err = m.invoiceService.Get().Reconcile(ctx, markup)
+		if err != nil {
+			return nil, m.settleNumbers(ctx, documents, fmt.Errorf("row %d: %w", i+1, err))
+		}
+
+		documents[i].pdf, err = m.pdfGenerator.Get().GeneratePDF(ctx, markup)
+		if err != nil {
+			return nil, m.settleNumbers(ctx, documents, fmt.Errorf("failed to render row %d: %v", i+1, err))
+		}
+	}
+
+	m.Logger().Info("Merged rows into template", "file", fileName, "rows", len(rows))
+
+	return documents, nil
+}
+
+func mergeRow(template []byte, row MergeRow) []byte {
+	markup := setHeaderAttributes(template, row.Attributes)
+	if len(row.Items) > 0 {
+		markup = replaceTableRows(markup, row.Items)
+	}
+	return markup
+}
+
+// parseMergeRows reads rows from either a JSON array of objects or a CSV file
+// with a header line. In CSV files the items column holds the line items as
+// `;` separated rows of `|` separated cells. Keys must be valid attribute
+// names, anything else could inject markup into the document header.
+func parseMergeRows(data []byte) ([]MergeRow, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseJSONMergeRows(trimmed)
+	}
+	return parseCSVMergeRows(trimmed)
+}
+
+func parseJSONMergeRows(data []byte) ([]MergeRow, error) {
+	var records []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid JSON rows: %v", err)
+	}
+
+	var rows []MergeRow
+	for i, record := range records {
+		row := MergeRow{Attributes: map[string]string{}}
+		for key, raw := range record {
+			if !attributeNamePattern.MatchString(key) {
+				return nil, fmt.Errorf("%w: invalid attribute name %q in row %d", errInvalidRequest, key, i+1)
+			}
+			if key == itemsField {
+				if err := json.Unmarshal(raw, &row.Items); err != nil {
+					return nil, fmt.Errorf("invalid items in row %d: %v", i+1, err)
+				}
+				continue
+			}
+
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				// Allow plain numbers and booleans as attribute values.
+				value = string(raw)
+			}
+			row.Attributes[key] = value
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseCSVMergeRows(data []byte) ([]MergeRow, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV rows: %v", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("CSV needs a header line and at least one row")
+	}
+
+	// Spreadsheets often start CSV files with a byte order mark
+	header := records[0]
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	for i, key := range header {
+		if header[i] = strings.TrimSpace(key); !attributeNamePattern.MatchString(header[i]) {
+			return nil, fmt.Errorf("%w: invalid attribute name %q in column %d", errInvalidRequest, key, i+1)
+		}
+	}
+
+	var rows []MergeRow
+	for _, record := range records[1:] {
+		row := MergeRow{Attributes: map[string]string{}}
+		for i, key := range header {
+			if key == itemsField {
+				row.Items = parseCSVItems(record[i])
+				continue
+			}
+			row.Attributes[key] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseCSVItems(value string) [][]string {
+	var items [][]string
+	for _, item := range strings.Split(value, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		items = append(items, strings.Split(item, "|"))
+	}
+	return items
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMergeRowsRejectsInvalidKeys(t *testing.T) {
+	inputs := []string{
+		`[{"name\n:toc:": "x"}]`,
+		`[{"": "x"}]`,
+		`[{"-flag": "x"}]`,
+		`[{"a b": "x"}]`,
+		"name,\"evil\n:include: /etc/passwd\"\nAlice,x\n",
+		"name,:date:\nAlice,x\n",
+		"name,\nAlice,x\n",
+	}
+	for _, input := range inputs {
+		if _, err := parseMergeRows([]byte(input)); !errors.Is(err, errInvalidRequest) {
+			t.Errorf("parseMergeRows(%q) returned %v, want errInvalidRequest", input, err)
+		}
+	}
+}
+
+func TestParseMergeRows(t *testing.T) {
+	rows, err := parseMergeRows([]byte("\ufeffinvoice_number, date ,items\nINV-1,1.1.2024,1|Beratung|100;2|Reise|50\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Attributes["invoice_number"] != "INV-1" || rows[0].Attributes["date"] != "1.1.2024" {
+		t.Fatalf("got %+v", rows)
+	}
+	if len(rows[0].Items) != 2 || rows[0].Items[1][1] != "Reise" {
+		t.Errorf("got items %q", rows[0].Items)
+	}
+
+	rows, err = parseMergeRows([]byte(`[{"invoice_number": "INV-2", "amount": 12.5, "items": [["1", "Beratung", "12,50"]]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].Attributes["amount"] != "12.5" || len(rows[0].Items) != 1 {
+		t.Errorf("got %+v", rows[0])
+	}
+}
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strconv"
+	"sync"
 	"text/template"
 
 	"github.com/ServiceWeaver/weaver"
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 )
 
 func main() {
@@ -24,7 +31,19 @@ type app struct {
 	aDocRepository    weaver.Ref[ADocRepository]
 	chatGPTRepository weaver.Ref[ChatGPTRepository]
 	speechRepository  weaver.Ref[SpeechRepository]
+	mailMerger        weaver.Ref[MailMerger]
+	invoiceService    weaver.Ref[InvoiceService]
+	sequenceService   weaver.Ref[SequenceService]
+	textToSpeech      weaver.Ref[TextToSpeech]
+	voiceArchive      weaver.Ref[VoiceArchive]
+	authenticator     weaver.Ref[Authenticator]
+	shareLinks        weaver.Ref[ShareLinks]
+	usageLedger       weaver.Ref[UsageLedger]
+	promptTemplates   weaver.Ref[PromptTemplates]
 	listener          weaver.Listener
+
+	// sharedRenders serializes the renders of share links, see sharedPDF
+	sharedRenders sync.Mutex
 }
 
 func serve(ctx context.Context, a *app) error {
@@ -32,6 +51,12 @@ func serve(ctx context.Context, a *app) error {
 	logger.Info("listener available on", a.listener)
 
 	router := mux.NewRouter()
+	router.Use(a.authenticate, a.canonicalizeDocument, a.authorize)
+	a.registerLogin(router)
+	a.registerSharing(router)
+	a.registerShareLinks(router)
+	a.registerPromptTemplates(router)
+	a.registerAPI(router)
 
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/list", http.StatusMovedPermanently)
@@ -41,22 +66,37 @@ func serve(ctx context.Context, a *app) error {
 		vars := mux.Vars(r)
 		fileName := vars["filename"]
 
-		content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
-		if err != nil {
+		version := 0
+		if v := r.URL.Query().Get("version"); v != "" {
+			var err error
+			version, err = strconv.Atoi(v)
+			if err != nil {
+				http.Error(w, "Invalid version", http.StatusBadRequest)
+				return
+			}
+		}
+
+		pdfContentBytes, hash, err := a.renderPDF(ctx, fileName, version)
+		if errors.Is(err, ErrNoSuchVersion) || errors.Is(err, fs.ErrNotExist) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			logger.Warn(err.Error())
 			return
-		}
-		pdfContentBytes, err := a.pdfGenerator.Get().GeneratePDF(ctx, content)
-		if err != nil {
+		} else if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			logger.Warn(err.Error())
 			return
 		}
+		if hash != "" {
+			w.Header().Set("X-Content-SHA256", hash)
+		}
 
 		// Serve the generated PDF
 		w.Header().Set("Content-Type", "application/pdf")
-		w.Header().Set("Content-Disposition", "inline; filename=output.pdf")
+		if r.URL.Query().Get("download") != "" {
+			w.Header().Set("Content-Disposition", "attachment; filename="+fileName+".pdf")
+		} else {
+			w.Header().Set("Content-Disposition", "inline; filename=output.pdf")
+		}
 		_, err = w.Write(pdfContentBytes)
 		if err != nil {
 			logger.Warn("Error writing response:", err)
@@ -82,6 +122,84 @@ func serve(ctx context.Context, a *app) error {
 		}
 	})
 
+	router.HandleFunc("/adoc/{filename}/attributes", func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			logger.Warn(err.Error())
+			return
+		}
+		attributes := parseHeaderAttributes(content)
+
+		if r.Method == http.MethodPut {
+			var changes []Attribute
+			err := json.NewDecoder(r.Body).Decode(&changes)
+			if err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			attributes, err = a.setAttributes(ctx, fileName, changes, requestUser(r).Name)
+			if errors.Is(err, errInvalidAttribute) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			} else if errors.Is(err, ErrDocumentLocked) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				logger.Warn(err.Error())
+				return
+			}
+		} else if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(attributes)
+		if err != nil {
+			logger.Warn("Error writing response:", err)
+		}
+	})
+
+	router.HandleFunc("/adoc/{filename}/state", func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		state, err := a.aDocRepository.Get().GetState(ctx, fileName)
+		a.writeState(w, state, err)
+	})
+
+	router.HandleFunc("/adoc/{filename}/finalize", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		state, err := a.finalizeDocument(ctx, fileName, requestUser(r).Name)
+		a.writeState(w, state, err)
+	})
+
+	router.HandleFunc("/adoc/{filename}/archive", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		state, err := a.aDocRepository.Get().Archive(ctx, fileName)
+		a.writeState(w, state, err)
+	})
+
 	router.HandleFunc("/iframe/{filename}", func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		fileName := vars["filename"]
@@ -105,7 +223,7 @@ func serve(ctx context.Context, a *app) error {
 	})
 
 	router.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
-		adocFiles, err := a.aDocRepository.Get().GetFiles(ctx)
+		adocFiles, err := a.aDocRepository.Get().GetVisibleFiles(ctx, requestUser(r).Name)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			logger.Warn(err.Error())
@@ -146,7 +264,12 @@ func serve(ctx context.Context, a *app) error {
 		fileName := vars["filename"]
 
 		type RequestBody struct {
-			Prompt string `json:"prompt"`
+			Prompt   string            `json:"prompt"`
+			Template string            `json:"template"`
+			Values   map[string]string `json:"values"`
+			NoCache  bool              `json:"noCache"`
+			// VoicePromptID is the archived clip the prompt was dictated with
+			VoicePromptID string `json:"voicePromptId"`
 		}
 
 		var requestBody RequestBody
@@ -156,28 +279,289 @@ func serve(ctx context.Context, a *app) error {
 			return
 		}
 
-		oldMarkup, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+		prompt, err := a.editPrompt(r, fileName, requestBody.Prompt, requestBody.Template, requestBody.Values)
+		if errors.Is(err, errInvalidRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, ErrNoSuchTemplate) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.Warn(err.Error())
+			return
+		}
+
+		change, err := a.changeDocument(ctx, fileName, prompt, requestUser(r).Name, requestBody.VoicePromptID, requestBody.NoCache)
+		if errors.Is(err, ErrDocumentLocked) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		} else if errors.Is(err, errInvalidRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, ErrProtectedContent) || errors.Is(err, ErrLockedContent) || errors.Is(err, ErrIncompleteChange) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		} else if errors.Is(err, ErrBudgetExceeded) {
+			http.Error(w, err.Error(), budgetStatus(err))
+			return
+		} else if errors.Is(err, ErrRemoteUnavailable) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			logger.Warn(err.Error())
+			return
+		} else if errors.Is(err, ErrInvoiceInconsistent) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			logger.Warn(err.Error())
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.Warn(err.Error())
+			return
+		}
+
+		type ResponseBody struct {
+			InvoiceReport
+			Blocked []BlockedDirective `json:"blocked,omitempty"`
+			Cached  bool               `json:"cached"`
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(ResponseBody{InvoiceReport: change.Invoice, Blocked: change.Blocked, Cached: change.Cached})
+		if err != nil {
+			logger.Warn("Error writing response:", err)
+		}
+	})
+
+	router.HandleFunc("/command/{filename}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		type RequestBody struct {
+			Prompt string `json:"prompt"`
+			// Version is the version shown, 0 for the newest.
+			Version int `json:"version"`
+		}
+
+		var requestBody RequestBody
+		err := json.NewDecoder(r.Body).Decode(&requestBody)
+		if err != nil || requestBody.Version < 0 {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		type ResponseBody struct {
+			Command VoiceCommand `json:"command"`
+			URL     string       `json:"url,omitempty"`
+			Text    string       `json:"text,omitempty"`
+		}
+
+		// Titles of the documents the user may switch to
+		documents := func() (map[string]string, error) {
+			fileNames, err := a.aDocRepository.Get().GetVisibleFiles(ctx, requestUser(r).Name)
+			if err != nil {
+				return nil, err
+			}
+			titles := make(map[string]string)
+			for _, name := range fileNames {
+				content, err := a.aDocRepository.Get().ReadFile(ctx, name)
+				if err != nil {
+					logger.Warn(err.Error())
+					continue
+				}
+				titles[name] = documentTitle(content)
+			}
+			return titles, nil
+		}
+
+		// Commands run right away, anything else is left to /change
+		command, err := parseVoiceCommand(requestBody.Prompt, documents)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			logger.Warn(err.Error())
 			return
 		}
+		response := ResponseBody{Command: command}
+		switch response.Command.Intent {
+		case CommandUndo:
+			err := a.authorizeRequest(r, fileName, RoleEditor)
+			if err == nil {
+				err = a.aDocRepository.Get().Undo(ctx, fileName)
+			}
+			if errors.Is(err, ErrForbidden) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			} else if errors.Is(err, ErrDocumentLocked) || errors.Is(err, ErrNothingToUndo) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				logger.Warn(err.Error())
+				return
+			}
+			response.URL = "/pdf/" + fileName
+		case CommandPrevious:
+			// Versions are numbered from the newest, the previous one of the
+			// version shown is the next number
+			versions, err := a.aDocRepository.Get().ListVersions(ctx, fileName)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				logger.Warn(err.Error())
+				return
+			}
+			previous := requestBody.Version + 1
+			if previous >= len(versions) {
+				http.Error(w, fmt.Sprintf("%v: version %d is the oldest", ErrNoSuchVersion, requestBody.Version), http.StatusNotFound)
+				return
+			}
+			response.URL = fmt.Sprintf("/pdf/%s?version=%d", fileName, previous)
+		case CommandDownload:
+			response.URL = "/pdf/" + fileName + "?download=1"
+		case CommandSwitch:
+			response.URL = "/iframe/" + response.Command.Document
+		case CommandRead:
+			content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				logger.Warn(err.Error())
+				return
+			}
+			response.Text = markupToText(content)
+			// Viewers get the text, reading it aloud is for editors
+			if a.authorizeRequest(r, fileName, RoleEditor) == nil {
+				response.URL = "/tts/" + fileName
+			}
+		}
 
-		newMarkup, err := a.chatGPTRepository.Get().ChangeMarkup(ctx, string(oldMarkup), requestBody.Prompt)
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
 		if err != nil {
+			logger.Warn("Error writing response:", err)
+		}
+	})
+
+	router.HandleFunc("/tts/{filename}", func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			logger.Warn(err.Error())
+			return
+		}
+
+		text := markupToText(content)
+		if section := r.URL.Query().Get("section"); section != "" {
+			var ok bool
+			text, ok = sectionToText(content, section)
+			if !ok {
+				http.Error(w, "No such section: "+section, http.StatusNotFound)
+				return
+			}
+		}
+
+		a.writeSpeech(ctx, w, text, documentLanguage(content))
+	})
+
+	router.HandleFunc("/tts/{filename}/changes", func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		newMarkup, err := a.aDocRepository.Get().ReadVersion(ctx, fileName, 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			logger.Warn(err.Error())
+			return
+		}
+		oldMarkup, err := a.aDocRepository.Get().ReadVersion(ctx, fileName, 1)
+		if errors.Is(err, ErrNoSuchVersion) {
+			http.Error(w, "Document has not been changed yet", http.StatusNotFound)
+			return
+		} else if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			logger.Warn(err.Error())
 			return
 		}
 
-		err = a.aDocRepository.Get().SaveVariantForFile(ctx, fileName, newMarkup)
+		a.writeSpeech(ctx, w, changeSummary(oldMarkup, newMarkup), documentLanguage(newMarkup))
+	})
+
+	router.HandleFunc("/voice-prompts", func(w http.ResponseWriter, r *http.Request) {
+		prompts, err := a.voiceArchive.Get().List(ctx, r.URL.Query().Get("document"))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			logger.Warn(err.Error())
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
+		// Only list the prompts of documents the user may edit
+		visible := []VoicePrompt{}
+		for _, prompt := range prompts {
+			if a.authorizeRequest(r, prompt.Document, RoleEditor) == nil {
+				visible = append(visible, prompt)
+			}
+		}
+		prompts = visible
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(prompts)
+		if err != nil {
+			logger.Warn("Error writing response:", err)
+		}
+	})
+
+	router.HandleFunc("/voice-prompts/{id}/audio", func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		prompt, audio, err := a.voiceArchive.Get().ReadAudio(ctx, vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			logger.Warn(err.Error())
+			return
+		}
+		if err := a.authorizeRequest(r, prompt.Document, RoleEditor); err != nil {
+			a.writeAuthorizationError(w, r, err)
+			return
+		}
+
+		format, err := detectAudioFormat(audio)
+		if err == nil {
+			w.Header().Set("Content-Type", format.contentType)
+		}
+		_, err = w.Write(audio)
+		if err != nil {
+			logger.Warn("Error writing response:", err)
+		}
+	})
+
+	router.HandleFunc("/invoice/{filename}", func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			logger.Warn(err.Error())
+			return
+		}
+
+		report, err := a.invoiceService.Get().Check(ctx, content)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.Warn(err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(report)
+		if err != nil {
+			logger.Warn("Error writing response:", err)
+		}
 	})
 
 	router.HandleFunc("/speech-to-text", func(w http.ResponseWriter, r *http.Request) {
@@ -186,13 +570,30 @@ func serve(ctx context.Context, a *app) error {
 			return
 		}
 
+		r.Body = http.MaxBytesReader(w, r.Body, 32<<20)
 		err := r.ParseMultipartForm(32 << 20) // Limit request size to 32MB
-		if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, "Voice prompt is too large", http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
 			http.Error(w, "Failed to parse multipart form", http.StatusBadRequest)
 			logger.Warn(err.Error())
 			return
 		}
 
+		fileName, err := canonicalDocument(r.FormValue("filename"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Viewers may not spend money on transcriptions
+		if err := a.authorizeRequest(r, fileName, RoleEditor); err != nil {
+			a.writeAuthorizationError(w, r, err)
+			return
+		}
+
 		file, _, err := r.FormFile("voicePrompt")
 		if err != nil {
 			http.Error(w, "Failed to retrieve voice prompt", http.StatusBadRequest)
@@ -208,22 +609,448 @@ func serve(ctx context.Context, a *app) error {
 			return
 		}
 
-		// Call the text-to-speech method to convert audio bytes to text
-		speechRepo := a.speechRepository.Get()
-		text, err := speechRepo.SpeechToText(r.Context(), audioBytes)
-		if err != nil {
+		text, voicePromptID, err := a.transcribe(r.Context(), audioBytes, r.FormValue("language"), fileName)
+		if errors.Is(err, errInvalidLanguage) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if errors.Is(err, ErrEmptyAudio) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, ErrAudioTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		} else if errors.Is(err, ErrUnsupportedAudio) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		} else if errors.Is(err, ErrRemoteUnavailable) {
+			http.Error(w, "Speech to text is unavailable, try again later", http.StatusServiceUnavailable)
+			logger.Warn(err.Error())
+			return
+		} else if err != nil {
 			http.Error(w, "Failed to convert speech to text", http.StatusInternalServerError)
 			logger.Warn(err.Error())
 			return
 		}
-		a.Logger().Info("Received response from Whisper API: " + text)
+		if voicePromptID != "" {
+			w.Header().Set("X-Voice-Prompt-ID", voicePromptID)
+		}
 
 		// Return the recognized text to the client
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(text))
 	})
 
+	router.HandleFunc("/merge/{filename}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		vars := mux.Vars(r)
+		fileName := vars["filename"]
+
+		r.Body = http.MaxBytesReader(w, r.Body, 32<<20)
+		err := r.ParseMultipartForm(32 << 20) // Limit request size to 32MB
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, "Rows are too large", http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			http.Error(w, "Failed to parse multipart form", http.StatusBadRequest)
+			logger.Warn(err.Error())
+			return
+		}
+
+		file, _, err := r.FormFile("rows")
+		if err != nil {
+			http.Error(w, "Failed to retrieve rows", http.StatusBadRequest)
+			logger.Warn(err.Error())
+			return
+		}
+		defer file.Close()
+
+		data, err := ioutil.ReadAll(file)
+		if err != nil {
+			http.Error(w, "Failed to read rows", http.StatusInternalServerError)
+			logger.Warn(err.Error())
+			return
+		}
+
+		rows, err := parseMergeRows(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Query().Get("output") == "folder" {
+			paths, err := a.mailMerger.Get().MergeToFolder(ctx, fileName, rows)
+			if errors.Is(err, ErrInvoiceInconsistent) {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				logger.Warn(err.Error())
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(paths)
+			return
+		}
+
+		zipBytes, err := a.mailMerger.Get().MergeToZip(ctx, fileName, rows)
+		if errors.Is(err, ErrInvoiceInconsistent) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logger.Warn(err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", "attachment; filename="+fileName+".zip")
+		_, err = w.Write(zipBytes)
+		if err != nil {
+			logger.Warn("Error writing response:", err)
+		}
+	})
+
+	upgrader := websocket.Upgrader{}
+
+	router.HandleFunc("/speech/stream", func(w http.ResponseWriter, r *http.Request) {
+		options, err := a.transcriptionOptions(ctx, r.URL.Query().Get("language"), r.URL.Query().Get("filename"))
+		if errors.Is(err, errInvalidLanguage) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			logger.Warn(err.Error())
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Warn(err.Error())
+			return
+		}
+		defer conn.Close()
+		conn.SetReadLimit(32 << 20)
+
+		// Binary messages carry audio chunks, any text message ends the recording
+		stream := newSpeechStream(a.speechRepository.Get(), options)
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					logger.Warn(err.Error())
+				}
+				return
+			}
+
+			if messageType == websocket.BinaryMessage {
+				text, ok, err := stream.write(r.Context(), data)
+				if errors.Is(err, ErrAudioTooLarge) {
+					conn.WriteJSON(speechStreamMessage{Type: "error", Error: err.Error()})
+					return
+				} else if err != nil {
+					// A failed partial transcript is no reason to stop listening
+					logger.Warn(err.Error())
+					continue
+				}
+				if ok {
+					conn.WriteJSON(speechStreamMessage{Type: "partial", Text: text})
+				}
+				continue
+			}
+
+			text, err := stream.finish(r.Context())
+			if err != nil {
+				conn.WriteJSON(speechStreamMessage{Type: "error", Error: err.Error()})
+				logger.Warn(err.Error())
+				return
+			}
+			a.Logger().Info("Received final text from speech stream: " + text)
+			voicePromptID := a.archiveVoicePrompt(ctx, r.URL.Query().Get("filename"), options, text, stream.audio)
+
+			conn.WriteJSON(speechStreamMessage{Type: "final", Text: text, VoicePromptID: voicePromptID})
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+	})
+
 	http.Handle("/", router)
 
 	return http.Serve(a.listener, nil)
 }
+
+var errInvalidLanguage = errors.New("invalid language code")
+
+// renderPDF returns the PDF of a version of a document. The newest version
+// of a finalized document is the frozen PDF, whose hash is returned as well.
+func (a *app) renderPDF(ctx context.Context, fileName string, version int) ([]byte, string, error) {
+	state, err := a.aDocRepository.Get().GetState(ctx, fileName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if state.Locked() && version == 0 {
+		// Serve exactly the PDF that was finalized instead of re-rendering
+		pdf, err := a.aDocRepository.Get().ReadFinalPDF(ctx, fileName)
+		return pdf, state.PDFHash, err
+	}
+
+	content, err := a.aDocRepository.Get().ReadVersion(ctx, fileName, version)
+	if err != nil {
+		return nil, "", err
+	}
+	pdf, err := a.pdfGenerator.Get().GeneratePDF(ctx, content)
+	return pdf, "", err
+}
+
+var errInvalidAttribute = errors.New("invalid attribute name")
+
+// setAttributes changes header attributes of a document and returns all of
+// its attributes afterwards.
+func (a *app) setAttributes(ctx context.Context, fileName string, attributes []Attribute, author string) ([]Attribute, error) {
+	values := make(map[string]string)
+	for _, attribute := range attributes {
+		if !attributeNamePattern.MatchString(attribute.Name) {
+			return nil, fmt.Errorf("%w: %s", errInvalidAttribute, attribute.Name)
+		}
+		values[attribute.Name] = attribute.Value
+	}
+
+	content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	content, reservation, err := a.sequenceService.Get().FillPlaceholders(ctx, fileName, setHeaderAttributes(content, values), false)
+	if err == nil {
+		err = a.aDocRepository.Get().SaveVariantForFile(ctx, fileName, content, author, "")
+	}
+	if err := a.settleNumbers(ctx, reservation, err); err != nil {
+		return nil, err
+	}
+	return parseHeaderAttributes(content), nil
+}
+
+// settleNumbers commits the numbers a change reserved once the markup using
+// them was saved. When the change failed they are released, so the next
+// document gets them and the sequence stays gap-free.
+func (a *app) settleNumbers(ctx context.Context, reservation string, err error) error {
+	if err != nil {
+		if releaseErr := a.sequenceService.Get().Release(ctx, reservation); releaseErr != nil {
+			a.Logger().Warn("Failed to release sequence numbers", "reservation", reservation, "err", releaseErr)
+		}
+		return err
+	}
+	return a.sequenceService.Get().Commit(ctx, reservation)
+}
+
+// writeState serves the lifecycle state of a document, or the error of
+// reading or changing it.
+func (a *app) writeState(w http.ResponseWriter, state DocumentState, err error) {
+	if errors.Is(err, ErrInvalidDocumentName) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if errors.Is(err, ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	} else if errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrNoSuchVersion) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if errors.Is(err, ErrDocumentLocked) || errors.Is(err, ErrInvalidTransition) || errors.Is(err, ErrDocumentChanged) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if errors.Is(err, ErrInvoiceInconsistent) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		a.Logger().Warn(err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		a.Logger().Warn("Error writing response:", err)
+	}
+}
+
+// finalizeDocument assigns pending numbers and fixes totals one last time,
+// then locks the document together with its rendered PDF.
+func (a *app) finalizeDocument(ctx context.Context, fileName string, author string) (DocumentState, error) {
+	content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+	if err != nil {
+		return DocumentState{}, err
+	}
+
+	finalMarkup, reservation, err := a.sequenceService.Get().FillPlaceholders(ctx, fileName, content, true)
+	if err == nil {
+		finalMarkup, _, err = a.invoiceService.Get().Reconcile(ctx, finalMarkup)
+	}
+	if err == nil && !bytes.Equal(finalMarkup, content) {
+		err = a.aDocRepository.Get().SaveVariantForFile(ctx, fileName, finalMarkup, author, "")
+	}
+	if err := a.settleNumbers(ctx, reservation, err); err != nil {
+		return DocumentState{}, err
+	}
+
+	pdf, err := a.pdfGenerator.Get().GeneratePDF(ctx, finalMarkup)
+	if err != nil {
+		return DocumentState{}, err
+	}
+	return a.aDocRepository.Get().Finalize(ctx, fileName, finalMarkup, pdf)
+}
+
+// documentChange is the outcome of a change that was saved.
+type documentChange struct {
+	Invoice InvoiceReport
+	// Blocked lists the directives removed from the generated markup.
+	Blocked []BlockedDirective
+	// Cached is set when GPT's answer came from the response cache.
+	Cached bool
+}
+
+// changeDocument lets GPT apply a prompt to the newest version of a document
+// and saves the result as a new variant after numbering and invoice checks.
+// Directives GPT was tricked into adding are removed and returned. Every GPT
+// call is recorded, and none is made once a budget is used up.
+func (a *app) changeDocument(ctx context.Context, fileName string, prompt string, author string, voicePromptID string, bypassCache bool) (documentChange, error) {
+	if voicePromptID != "" && !voicePromptIDPattern.MatchString(voicePromptID) {
+		return documentChange{}, fmt.Errorf("%w: invalid voice prompt id %q", errInvalidRequest, voicePromptID)
+	}
+
+	state, err := a.aDocRepository.Get().GetState(ctx, fileName)
+	if err != nil {
+		return documentChange{}, err
+	}
+	if state.Locked() {
+		return documentChange{}, ErrDocumentLocked
+	}
+
+	oldMarkup, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+	if err != nil {
+		return documentChange{}, err
+	}
+
+	estimate, err := a.chatGPTRepository.Get().EstimateUsage(ctx, string(oldMarkup), prompt)
+	if err != nil {
+		return documentChange{}, err
+	}
+	if err := a.usageLedger.Get().CheckBudget(ctx, author, fileName, estimate); err != nil {
+		return documentChange{}, err
+	}
+	newMarkup, usage, err := a.chatGPTRepository.Get().ChangeMarkup(ctx, string(oldMarkup), prompt, bypassCache)
+	if usage.PromptTokens+usage.CompletionTokens > 0 {
+		record := UsageRecord{User: author, Document: fileName, Usage: usage}
+		if _, err := a.usageLedger.Get().Record(ctx, record); err != nil {
+			a.Logger().Warn("Failed to record LLM usage", "err", err)
+		}
+	}
+	if err != nil {
+		return documentChange{}, err
+	}
+	change := documentChange{Cached: usage.Cached}
+
+	newMarkup, change.Blocked, err = a.pdfGenerator.Get().GuardMarkup(ctx, newMarkup)
+	if err != nil {
+		return documentChange{}, err
+	}
+	for _, directive := range change.Blocked {
+		a.Logger().Warn("Blocked directive in generated markup", "document", fileName, "line", directive.Line, "directive", directive.Directive, "reason", directive.Reason)
+	}
+
+	newMarkup, reservation, err := a.sequenceService.Get().FillPlaceholders(ctx, fileName, newMarkup, false)
+	if err == nil {
+		newMarkup, change.Invoice, err = a.invoiceService.Get().Reconcile(ctx, newMarkup)
+	}
+	if err == nil {
+		err = a.aDocRepository.Get().SaveVariantForFile(ctx, fileName, newMarkup, author, voicePromptID)
+	}
+	return change, a.settleNumbers(ctx, reservation, err)
+}
+
+// transcribe turns a voice prompt for a document into text and archives it.
+// It returns the text and the id of the archived clip, if any.
+func (a *app) transcribe(ctx context.Context, audio []byte, language string, fileName string) (string, string, error) {
+	options, err := a.transcriptionOptions(ctx, language, fileName)
+	if err != nil {
+		return "", "", err
+	}
+
+	text, err := a.speechRepository.Get().SpeechToText(ctx, audio, options)
+	if err != nil {
+		return "", "", err
+	}
+	a.Logger().Info("Received response from Whisper API: " + text)
+
+	return text, a.archiveVoicePrompt(ctx, fileName, options, text, audio), nil
+}
+
+// archiveVoicePrompt keeps a transcribed clip if the voice archive is enabled
+// and returns its id. Failing to archive never fails the transcription.
+func (a *app) archiveVoicePrompt(ctx context.Context, document string, options TranscriptionOptions, transcript string, audio []byte) string {
+	id, err := a.voiceArchive.Get().Store(ctx, VoicePrompt{
+		Document:   document,
+		Transcript: transcript,
+		Language:   options.Language,
+	}, audio)
+	if err != nil {
+		a.Logger().Warn("Failed to archive voice prompt", "err", err)
+		return ""
+	}
+	return id
+}
+
+// writeSpeech reads a text aloud and serves the audio.
+func (a *app) writeSpeech(ctx context.Context, w http.ResponseWriter, text string, language string) {
+	speech, err := a.textToSpeech.Get().Synthesize(ctx, text, language)
+	if errors.Is(err, ErrRemoteUnavailable) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		a.Logger().Warn(err.Error())
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		a.Logger().Warn(err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", speech.ContentType)
+	_, err = w.Write(speech.Audio)
+	if err != nil {
+		a.Logger().Warn("Error writing response:", err)
+	}
+}
+
+// transcriptionOptions builds the hints for a voice prompt. Without an
+// explicit language the language of the document the prompt is meant for is
+// used, and its headings and attributes become the vocabulary.
+func (a *app) transcriptionOptions(ctx context.Context, language string, fileName string) (TranscriptionOptions, error) {
+	var options TranscriptionOptions
+	if language != "" {
+		normalized, ok := normalizeLanguage(language)
+		if !ok {
+			return options, fmt.Errorf("%w: %s", errInvalidLanguage, language)
+		}
+		options.Language = normalized
+	}
+
+	if fileName != "" {
+		content, err := a.aDocRepository.Get().ReadFile(ctx, fileName)
+		if err != nil {
+			return options, err
+		}
+		if options.Language == "" {
+			options.Language = documentLanguage(content)
+		}
+		options.Prompt = transcriptionVocabulary(content)
+	}
+
+	return options, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrIncompleteChange is returned when GPT's answer was cut off or dropped
+// sections of the document that the prompt did not ask about.
+var ErrIncompleteChange = errors.New("change lost part of the document")
+
+var (
+	sectionPattern        = regexp.MustCompile(`^==+\s+\S`)
+	blockDelimiterPattern = regexp.MustCompile("^(-{4,}|\\.{4,}|={4,}|\\*{4,}|_{4,}|/{4,}|\\+{4,}|\\|===|```)\\s*$")
+)
+
+// estimateTokens is a conservative guess of the tokens GPT needs for a text.
+// Most English words take a token per four bytes, German and markup need more.
+func estimateTokens(text string) int {
+	return (len(text) + 2) / 3
+}
+
+// splitMarkup cuts a document into chunks of about limit tokens. Cuts are
+// made before section headings, and between paragraphs of sections that are
+// too long on their own, but never inside the header or a delimited block. A
+// paragraph longer than limit stays whole.
+func splitMarkup(markup string, limit int) []string {
+	lines := strings.Split(strings.TrimRight(markup, "\n"), "\n")
+	if estimateTokens(markup) <= limit {
+		return []string{markup}
+	}
+
+	// Units are sections, or the paragraphs of long sections
+	var units [][]string
+	start, inBlock := 0, ""
+	sectionStarts := []int{}
+	paragraphStarts := map[int]bool{}
+	for i := headerEnd(lines); i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if inBlock != "" {
+			if line == inBlock {
+				inBlock = ""
+			}
+			continue
+		}
+		if blockDelimiterPattern.MatchString(line) {
+			inBlock = line
+			continue
+		}
+		if sectionPattern.MatchString(line) && i > 0 {
+			sectionStarts = append(sectionStarts, i)
+		} else if i > 0 && strings.TrimSpace(lines[i-1]) == "" && strings.TrimSpace(line) != "" {
+			paragraphStarts[i] = true
+		}
+	}
+	for _, next := range append(sectionStarts, len(lines)) {
+		if next <= start {
+			continue
+		}
+		section := lines[start:next]
+		if estimateTokens(strings.Join(section, "\n")) <= limit {
+			units = append(units, section)
+		} else {
+			// The heading stays with the first paragraph
+			from, first := start, true
+			for i := start + 1; i < next; i++ {
+				if paragraphStarts[i] && first {
+					first = false
+				} else if paragraphStarts[i] {
+					units = append(units, lines[from:i])
+					from = i
+				}
+			}
+			units = append(units, lines[from:next])
+		}
+		start = next
+	}
+
+	var chunks []string
+	var current []string
+	for _, unit := range units {
+		if len(current) > 0 && estimateTokens(strings.Join(append(current, unit...), "\n")) > limit {
+			chunks = append(chunks, strings.Join(current, "\n"))
+			current = nil
+		}
+		current = append(current, unit...)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, "\n"))
+	}
+	return chunks
+}
+
+// joinChunks puts the changed chunks back together, one blank line apart.
+func joinChunks(chunks []string) string {
+	var parts []string
+	for _, chunk := range chunks {
+		if chunk = strings.Trim(chunk, "\n"); chunk != "" {
+			parts = append(parts, chunk)
+		}
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// checkChunk rejects a changed chunk that is empty or lost section headings,
+// unless the prompt mentions the lost sections.
+func checkChunk(oldChunk string, newChunk string, prompt string, part string) error {
+	if strings.TrimSpace(newChunk) == "" && strings.TrimSpace(oldChunk) != "" {
+		return fmt.Errorf("%w: part %s came back empty", ErrIncompleteChange, part)
+	}
+
+	newHeadings := make(map[string]bool)
+	oldCount, newCount := 0, 0
+	for _, line := range strings.Split(newChunk, "\n") {
+		if match := headingPattern.FindStringSubmatch(strings.TrimRight(line, "\r")); match != nil {
+			newHeadings[match[0]] = true
+			newCount++
+		}
+	}
+	var lost []string
+	for _, line := range strings.Split(oldChunk, "\n") {
+		if match := headingPattern.FindStringSubmatch(strings.TrimRight(line, "\r")); match != nil {
+			oldCount++
+			if !newHeadings[match[0]] && !mentions(prompt, []string{match[1]}) {
+				lost = append(lost, match[1])
+			}
+		}
+	}
+	// Renamed headings, e.g. by a translation, keep their number
+	if newCount < oldCount && len(lost) > 0 {
+		return fmt.Errorf("%w: part %s dropped %s", ErrIncompleteChange, part, strings.Join(lost, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// longDocument has a header, sections of a few paragraphs and a listing
+// with blank lines that must not be cut.
+func longDocument() string {
+	paragraph := strings.Repeat("Lorem ipsum dolor sit amet. ", 10)
+	var b strings.Builder
+	b.WriteString("= Handbook\n:lang: de\n:iban: DE89 3704 0044 0532 0130 00\n")
+	for _, title := range []string{"Intro", "Setup", "Usage", "Appendix"} {
+		b.WriteString("\n== " + title + "\n\n" + paragraph + "\n\n" + paragraph + "\n")
+		if title == "Setup" {
+			b.WriteString("\n----\nstep one\n\nstep two\n\nstep three\n----\n")
+		}
+		b.WriteString("\n" + paragraph + "\n")
+	}
+	return b.String()
+}
+
+func TestSplitMarkupRoundTrips(t *testing.T) {
+	markup := longDocument()
+	for _, limit := range []int{100, 200, 400, 10000} {
+		chunks := splitMarkup(markup, limit)
+		if limit < estimateTokens(markup) && len(chunks) < 2 {
+			t.Errorf("limit %d: got %d chunks, want the document split", limit, len(chunks))
+		}
+		if got := joinChunks(chunks); got != markup {
+			t.Errorf("limit %d: joined chunks differ from the document:\n%s", limit, got)
+		}
+	}
+}
+
+func TestSplitMarkupKeepsHeaderAndBlocksWhole(t *testing.T) {
+	chunks := splitMarkup(longDocument(), 100)
+
+	if !strings.HasPrefix(chunks[0], "= Handbook\n:lang: de\n:iban: DE89 3704 0044 0532 0130 00\n") {
+		t.Errorf("first chunk does not start with the whole header:\n%s", chunks[0])
+	}
+	for i, chunk := range chunks[1:] {
+		if strings.Contains(chunk, ":iban:") {
+			t.Errorf("chunk %d has header lines:\n%s", i+2, chunk)
+		}
+	}
+
+	for i, chunk := range chunks {
+		if strings.Count(chunk, "----")%2 != 0 {
+			t.Errorf("chunk %d cuts the listing block:\n%s", i+1, chunk)
+		}
+	}
+}
+
+func TestSplitMarkupCutsBeforeSections(t *testing.T) {
+	chunks := splitMarkup(longDocument(), 400)
+	for i, chunk := range chunks[1:] {
+		if !strings.HasPrefix(chunk, "== ") {
+			t.Errorf("chunk %d does not start with a section heading:\n%s", i+2, chunk)
+		}
+	}
+}
+
+func TestSplitMarkupKeepsLongParagraphWhole(t *testing.T) {
+	long := strings.Repeat("word ", 500)
+	markup := "= Letter\n\n== Body\n\nShort.\n\n" + long + "\n\nShort again.\n"
+	chunks := splitMarkup(markup, 100)
+
+	found := false
+	for _, chunk := range chunks {
+		if strings.Contains(chunk, long) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("the long paragraph was cut:\n%q", chunks)
+	}
+	if got := joinChunks(chunks); got != markup {
+		t.Errorf("joined chunks differ from the document:\n%s", got)
+	}
+}
+
+func TestCheckChunk(t *testing.T) {
+	old := "== Setup\n\nText\n\n== Usage\n\nMore text\n"
+	tests := []struct {
+		name     string
+		newChunk string
+		prompt   string
+		complete bool
+	}{
+		{"changed text", "== Setup\n\nNew text\n\n== Usage\n\nMore text\n", "rewrite", true},
+		{"empty answer", "\n", "rewrite", false},
+		{"dropped section", "== Setup\n\nText\n", "fix typos", false},
+		{"dropped section the prompt names", "== Setup\n\nText\n", "remove the Usage section", true},
+		{"translated headings", "== Einrichtung\n\nText\n\n== Nutzung\n\nMehr Text\n", "translate to German", true},
+	}
+	for _, test := range tests {
+		err := checkChunk(old, test.newChunk, test.prompt, "1 of 2")
+		if test.complete && err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !test.complete && !errors.Is(err, ErrIncompleteChange) {
+			t.Errorf("%s: got %v, want ErrIncompleteChange", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+var (
+	blockMacroPattern  = regexp.MustCompile(`^(include|image|video|audio)::([^\[]*)\[(.*)\]\s*$`)
+	inlineImagePattern = regexp.MustCompile(`image:([^:\s\[][^\s\[]*)\[([^\]]*)\]`)
+	uriSchemePattern   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+	// imageValuePattern matches image attributes set with the macro syntax,
+	// e.g. ":front-cover-image: image:cover.pdf[]".
+	imageValuePattern = regexp.MustCompile(`^image:(.*)\[.*\]$`)
+)
+
+// pathAttributes are read as folders or files by asciidoctor-pdf.
+var pathAttributes = map[string]bool{
+	"imagesdir":     true,
+	"iconsdir":      true,
+	"docinfodir":    true,
+	"stylesdir":     true,
+	"pdf-themesdir": true,
+	"pdf-fontsdir":  true,
+	"pdf-theme":     true,
+	"pdf-style":     true,
+	"pdf-stylesdir": true,
+}
+
+// imageAttributes are images asciidoctor-pdf puts on pages, either a path or
+// an image macro. They are resolved against imagesdir.
+var imageAttributes = map[string]bool{
+	"front-cover-image":           true,
+	"back-cover-image":            true,
+	"title-logo-image":            true,
+	"title-page-background-image": true,
+	"page-background-image":       true,
+	"page-background-image-recto": true,
+	"page-background-image-verso": true,
+}
+
+// BlockedDirective is a line of markup that was removed because it would
+// have read a file outside of the allowed folders or a remote resource.
+type BlockedDirective struct {
+	weaver.AutoMarshal
+	Line      int    `json:"line"`
+	Directive string `json:"directive"`
+	Reason    string `json:"reason"`
+}
+
+// guardMarkup removes includes, images and path attributes that point
+// outside of the roots. Block directives are dropped, inline images are
+// replaced by their alt text.
+func guardMarkup(markup []byte, roots []string) ([]byte, []BlockedDirective) {
+	var result bytes.Buffer
+	var blocked []BlockedDirective
+	imagesDir := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(markup))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(markup)+1)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		block := func(directive string, reason string) {
+			blocked = append(blocked, BlockedDirective{Line: lineNumber, Directive: directive, Reason: reason})
+		}
+
+		if match := blockMacroPattern.FindStringSubmatch(line); match != nil {
+			target := match[2]
+			if match[1] == "image" {
+				target = joinImagesDir(imagesDir, target)
+			}
+			if reason := disallowedTarget(target, roots); reason != "" {
+				block(line, reason)
+				continue
+			}
+		}
+
+		if match := attributeLinePattern.FindStringSubmatch(line); match != nil {
+			name, value := strings.ToLower(match[1]), strings.TrimSpace(match[2])
+			if name == "allow-uri-read" {
+				block(line, "reading remote resources is not allowed")
+				continue
+			}
+			if pathAttributes[name] {
+				if reason := disallowedTarget(value, roots); reason != "" {
+					block(line, reason)
+					continue
+				}
+				if name == "imagesdir" {
+					imagesDir = value
+				}
+			}
+			if imageAttributes[name] {
+				target := value
+				if match := imageValuePattern.FindStringSubmatch(value); match != nil {
+					target = match[1]
+				}
+				if reason := disallowedTarget(joinImagesDir(imagesDir, target), roots); reason != "" {
+					block(line, reason)
+					continue
+				}
+			}
+		}
+
+		line = inlineImagePattern.ReplaceAllStringFunc(line, func(macro string) string {
+			match := inlineImagePattern.FindStringSubmatch(macro)
+			if reason := disallowedTarget(joinImagesDir(imagesDir, match[1]), roots); reason != "" {
+				block(macro, reason)
+				alt, _, _ := strings.Cut(match[2], ",")
+				return alt
+			}
+			return macro
+		})
+
+		result.WriteString(line)
+		result.WriteByte('\n')
+	}
+
+	if len(blocked) == 0 {
+		return markup, nil
+	}
+	return result.Bytes(), blocked
+}
+
+// disallowedTarget returns why a file may not be read, or "" if it is inside
+// one of the roots. Relative targets are read from the adocs folder, which
+// asciidoctor-pdf gets as base directory.
+func disallowedTarget(target string, roots []string) string {
+	target = strings.TrimSpace(target)
+	switch {
+	case target == "":
+		return ""
+	case strings.Contains(target, "{"):
+		return "targets must not use attribute references"
+	case uriSchemePattern.MatchString(target):
+		return "remote resources are not allowed"
+	case filepath.IsAbs(target) || strings.HasPrefix(target, "/") || strings.HasPrefix(target, `\`):
+		return "absolute paths are not allowed"
+	}
+
+	clean := filepath.Join(adocsDirName, filepath.FromSlash(target))
+	for _, root := range roots {
+		rel, err := filepath.Rel(filepath.Clean(root), clean)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("only files below %s may be read", strings.Join(roots, ", "))
+}
+
+// joinImagesDir resolves an image target the way asciidoctor does.
+func joinImagesDir(imagesDir string, target string) string {
+	if imagesDir == "" || uriSchemePattern.MatchString(target) || filepath.IsAbs(target) || strings.HasPrefix(target, "/") {
+		return target
+	}
+	return imagesDir + "/" + target
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGuardMarkupBlocksHostileDirectives(t *testing.T) {
+	roots := []string{adocsDirName}
+	hostile := []string{
+		"include::/etc/passwd[]",
+		"include::../work/sessions.json[]",
+		"include::https://example.com/secret.adoc[]",
+		"include::{docdir}/../work/usage.json[]",
+		"image::/etc/passwd[]",
+		"image::../work/voice/clip.webm[]",
+		"image::https://example.com/track.png[]",
+		"video::file:///etc/passwd[]",
+		"Logo: image:../work/logo.png[Logo] here",
+		"Logo: image:http://example.com/logo.png[Logo] here",
+		":imagesdir: /etc",
+		":imagesdir: ..",
+		":docinfodir: ../work",
+		":pdf-themesdir: /tmp",
+		":pdf-fontsdir: ../work",
+		":pdf-theme: /etc/passwd",
+		":pdf-theme: ../work/theme.yml",
+		":pdf-style: /etc/passwd",
+		":front-cover-image: /etc/passwd",
+		":front-cover-image: image:/etc/passwd[]",
+		":front-cover-image: image:../work/cover.pdf[]",
+		":back-cover-image: /etc/passwd",
+		":title-logo-image: image:https://example.com/logo.png[]",
+		":title-page-background-image: ../work/bg.png",
+		":page-background-image: /etc/passwd",
+		":page-background-image-recto: image:/etc/passwd[]",
+		":page-background-image-verso: ../../secret.png",
+		":allow-uri-read:",
+	}
+	for _, directive := range hostile {
+		markup := "= Document\n" + directive + "\n\nText\n"
+		guarded, blocked := guardMarkup([]byte(markup), roots)
+		if len(blocked) != 1 {
+			t.Errorf("%s: %d directives blocked, want 1", directive, len(blocked))
+			continue
+		}
+		if blocked[0].Line != 2 {
+			t.Errorf("%s: blocked line %d, want 2", directive, blocked[0].Line)
+		}
+		if strings.Contains(string(guarded), "passwd") || strings.Contains(string(guarded), "work/") || strings.Contains(string(guarded), "://") {
+			t.Errorf("%s: guarded markup still reads it:\n%s", directive, guarded)
+		}
+	}
+}
+
+func TestGuardMarkupAllowsFilesBelowRoots(t *testing.T) {
+	markup := strings.Join([]string{
+		"= Document",
+		":imagesdir: images",
+		":front-cover-image: image:cover.pdf[]",
+		":title-logo-image: logo.png",
+		":pdf-theme: default",
+		"",
+		"include::parts/terms.adoc[]",
+		"image::chart.png[]",
+		"Logo: image:logo.png[Logo]",
+		"",
+	}, "\n")
+	guarded, blocked := guardMarkup([]byte(markup), []string{adocsDirName})
+	if len(blocked) != 0 || string(guarded) != markup {
+		t.Errorf("blocked %v in markup below the roots", blocked)
+	}
+}
+
+func TestGuardMarkupResolvesImagesDir(t *testing.T) {
+	markup := ":imagesdir: images\n\nimage::../../work/secret.png[]\n"
+	if _, blocked := guardMarkup([]byte(markup), []string{adocsDirName}); len(blocked) != 1 {
+		t.Errorf("got %d blocked, want the image that leaves adocs through imagesdir", len(blocked))
+	}
+
+	markup = "image::logo.png[]\n\nimage::shared/logo.png[]\n"
+	if _, blocked := guardMarkup([]byte(markup), []string{"adocs/shared"}); len(blocked) != 1 || blocked[0].Line != 1 {
+		t.Errorf("blocked %v, want the image outside of adocs/shared", blocked)
+	}
+}
+
+func TestGuardMarkupReplacesInlineImagesByAltText(t *testing.T) {
+	guarded, _ := guardMarkup([]byte("Logo: image:/etc/logo.png[Our logo,200] here\n"), []string{adocsDirName})
+	if string(guarded) != "Logo: Our logo here\n" {
+		t.Errorf("got %q", guarded)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type oidcConfig struct {
+	// Issuer is the URL of the provider, e.g. http://localhost:8081/default
+	// for a local mock issuer.
+	Issuer string `toml:"issuer"`
+	// ClientID of sudocu at the provider. The client secret is read from
+	// the SUDOCU_OIDC_CLIENT_SECRET environment variable.
+	ClientID string `toml:"client_id"`
+	// RedirectURL is the /auth/callback URL of sudocu as registered at the
+	// provider.
+	RedirectURL string `toml:"redirect_url"`
+	// UserClaim names the claim of the ID token that is used as user name,
+	// "email" by default. The subject is used if the claim is missing.
+	UserClaim string `toml:"user_claim"`
+}
+
+// oidcProvider is the discovered configuration of an OpenID Connect provider.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+func discoverOIDCProvider(ctx context.Context, issuer string) (*oidcProvider, error) {
+	var provider oidcProvider
+	err := getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %v", err)
+	}
+	if provider.Issuer != issuer {
+		return nil, fmt.Errorf("OIDC provider claims to be %q instead of %q", provider.Issuer, issuer)
+	}
+	return &provider, nil
+}
+
+func (p *oidcProvider) authorizationURL(config oidcConfig, state string, nonce string) string {
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {config.ClientID},
+		"redirect_uri":  {config.RedirectURL},
+		"scope":         {"openid email profile"},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+
+	separator := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// exchange redeems an authorization code and returns the claims of the
+// verified ID token.
+func (p *oidcProvider) exchange(ctx context.Context, config oidcConfig, code string, nonce string) (map[string]interface{}, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {config.RedirectURL},
+		"client_id":    {config.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(os.Getenv("SUDOCU_OIDC_CLIENT_SECRET")))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem code: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, err
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("token endpoint returned no ID token")
+	}
+
+	return p.verify(ctx, tokens.IDToken, config.ClientID, nonce)
+}
+
+// verify checks the RS256 signature and the claims of an ID token.
+func (p *oidcProvider) verify(ctx context.Context, token string, clientID string, nonce string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed ID token")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Algorithm != "RS256" {
+		return nil, fmt.Errorf("unsupported ID token algorithm %q", header.Algorithm)
+	}
+
+	key, err := p.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token signature")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("invalid ID token signature")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if claims["iss"] != p.Issuer {
+		return nil, fmt.Errorf("ID token was issued by %v", claims["iss"])
+	}
+	if !audienceContains(claims["aud"], clientID) {
+		return nil, fmt.Errorf("ID token is not meant for %s", clientID)
+	}
+	expires, _ := claims["exp"].(float64)
+	if time.Now().Unix() > int64(expires) {
+		return nil, fmt.Errorf("ID token expired")
+	}
+	if claims["nonce"] != nonce {
+		return nil, fmt.Errorf("ID token nonce does not match")
+	}
+
+	return claims, nil
+}
+
+// key returns a signing key of the provider. The keys are fetched again when
+// an unknown key id shows up, which happens when the provider rotates keys.
+func (p *oidcProvider) key(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[keyID]; ok {
+		return key, nil
+	}
+
+	var jwks struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, p.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC keys: %v", err)
+	}
+
+	p.keys = make(map[string]*rsa.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyType != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+		p.keys[jwk.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown ID token key %q", keyID)
+	}
+	return key, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("malformed ID token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("malformed ID token: %v", err)
+	}
+	return nil
+}
+
+// audienceContains handles both forms of the aud claim, a string and a list.
+func audienceContains(audience interface{}, clientID string) bool {
+	switch audience := audience.(type) {
+	case string:
+		return audience == clientID
+	case []interface{}:
+		for _, entry := range audience {
+			if entry == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s failed with status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
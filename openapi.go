@@ -0,0 +1,220 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var apiPathParameterPattern = regexp.MustCompile(`\{([a-z]+)\}`)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// openAPISpec describes the API routes as an OpenAPI 3 document. The schemas
+// are derived from the request and response types by reflection.
+func openAPISpec(routes []apiRoute) map[string]interface{} {
+	schemas := make(map[string]interface{})
+	errorSchema := schemaFor(reflect.TypeOf(apiError{}), schemas)
+
+	paths := make(map[string]interface{})
+	for _, route := range routes {
+		operation := map[string]interface{}{
+			"summary":     route.summary,
+			"operationId": operationID(route),
+			"responses": map[string]interface{}{
+				"default": map[string]interface{}{
+					"description": "Error",
+					"content":     jsonContent(errorSchema),
+				},
+			},
+		}
+
+		var parameters []interface{}
+		for _, match := range apiPathParameterPattern.FindAllStringSubmatch(route.path, -1) {
+			schema := map[string]interface{}{"type": "string"}
+			if match[1] == "version" {
+				schema = map[string]interface{}{"type": "integer"}
+			}
+			parameters = append(parameters, parameter(match[1], "path", schema))
+		}
+		for _, name := range route.query {
+			parameters = append(parameters, parameter(name, "query", map[string]interface{}{"type": "string"}))
+		}
+		if route.paginated {
+			parameters = append(parameters,
+				parameter("page", "query", map[string]interface{}{"type": "integer", "minimum": 1, "default": 1}),
+				parameter("per_page", "query", map[string]interface{}{"type": "integer", "minimum": 1, "maximum": apiMaxPerPage, "default": apiDefaultPerPage}))
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+
+		if route.request != nil {
+			contentType := route.requestType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					contentType: map[string]interface{}{"schema": schemaFor(reflect.TypeOf(route.request), schemas)},
+				},
+			}
+		}
+
+		status := "200"
+		if route.created {
+			status = "201"
+		}
+		success := map[string]interface{}{"description": "Success"}
+		switch {
+		case route.responseType != "":
+			success["content"] = map[string]interface{}{
+				route.responseType: map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+			}
+		case route.paginated:
+			success["content"] = jsonContent(map[string]interface{}{
+				"type":     "object",
+				"required": []string{"items", "page", "perPage", "total"},
+				"properties": map[string]interface{}{
+					"items":   map[string]interface{}{"type": "array", "items": schemaFor(reflect.TypeOf(route.response), schemas)},
+					"page":    map[string]interface{}{"type": "integer"},
+					"perPage": map[string]interface{}{"type": "integer"},
+					"total":   map[string]interface{}{"type": "integer"},
+				},
+			})
+		case route.response != nil:
+			success["content"] = jsonContent(schemaFor(reflect.TypeOf(route.response), schemas))
+		default:
+			success["content"] = jsonContent(map[string]interface{}{"type": "object"})
+		}
+		operation["responses"].(map[string]interface{})[status] = success
+
+		path := apiPrefix + route.path
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[path] = item
+		}
+		item[strings.ToLower(route.method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "sudocu",
+			"version": "1",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+// operationID turns "GET /documents/{name}/variants" into
+// "getDocumentsNameVariants".
+func operationID(route apiRoute) string {
+	id := strings.ToLower(route.method)
+	if route.method == http.MethodGet && route.paginated {
+		id = "list"
+	}
+	for _, part := range strings.FieldsFunc(route.path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '.'
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+func parameter(name string, in string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     name,
+		"in":       in,
+		"required": in == "path",
+		"schema":   schema,
+	}
+}
+
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schema},
+	}
+}
+
+// schemaFor returns the JSON schema of a type. Named structs are added to
+// schemas and referenced, so that shared types are described only once.
+func schemaFor(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]interface{}{"type": "integer", "description": "nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem(), schemas)
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "binary"}
+		}
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := strings.TrimPrefix(t.Name(), "api")
+		if _, ok := schemas[name]; ok {
+			return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+		}
+		// Reserve the name first, types may refer to themselves
+		schemas[name] = nil
+		schemas[name] = structSchema(t, schemas)
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Embedded fields are markers like weaver.AutoMarshal
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemaFor(field.Type, schemas)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
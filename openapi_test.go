@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/gorilla/mux"
+)
+
+// TestOpenAPISpecMatchesRouter walks the routes the API registers and checks
+// that the spec describes exactly those, with their path parameters.
+func TestOpenAPISpecMatchesRouter(t *testing.T) {
+	a := &app{}
+	router := mux.NewRouter()
+	a.registerAPI(router)
+
+	var registered []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, apiPrefix+"/") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			registered = append(registered, method+" "+path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := openAPISpec(a.apiRoutes())
+	var described []string
+	for path, item := range spec["paths"].(map[string]interface{}) {
+		for method, operation := range item.(map[string]interface{}) {
+			described = append(described, strings.ToUpper(method)+" "+path)
+			checkOperation(t, strings.ToUpper(method)+" "+path, operation.(map[string]interface{}))
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(described)
+	if !reflect.DeepEqual(registered, described) {
+		t.Errorf("registered routes\n%s\ndiffer from the spec\n%s", strings.Join(registered, "\n"), strings.Join(described, "\n"))
+	}
+}
+
+// checkOperation checks that an operation declares its path parameters, the
+// shared error body and a success response.
+func checkOperation(t *testing.T, name string, operation map[string]interface{}) {
+	t.Helper()
+	path := strings.SplitN(name, " ", 2)[1]
+
+	declared := map[string]bool{}
+	parameters, _ := operation["parameters"].([]interface{})
+	for _, p := range parameters {
+		p := p.(map[string]interface{})
+		if p["in"] == "path" {
+			declared[p["name"].(string)] = true
+		}
+	}
+	for _, match := range apiPathParameterPattern.FindAllStringSubmatch(path, -1) {
+		if !declared[match[1]] {
+			t.Errorf("%s does not declare the path parameter %s", name, match[1])
+		}
+		delete(declared, match[1])
+	}
+	if len(declared) > 0 {
+		t.Errorf("%s declares unknown path parameters %v", name, declared)
+	}
+
+	responses := operation["responses"].(map[string]interface{})
+	errorResponse, _ := json.Marshal(responses["default"])
+	if !strings.Contains(string(errorResponse), `"$ref":"#/components/schemas/Error"`) {
+		t.Errorf("%s does not answer errors with the Error schema: %s", name, errorResponse)
+	}
+	if responses["200"] == nil && responses["201"] == nil {
+		t.Errorf("%s has no success response", name)
+	}
+}
+
+func TestOpenAPISpecSharedShapes(t *testing.T) {
+	schemas := map[string]interface{}{}
+	schemaFor(reflect.TypeOf(apiError{}), schemas)
+	errorSchema, _ := json.Marshal(schemas)
+	for _, field := range []string{`"error"`, `"code"`, `"message"`} {
+		if !strings.Contains(string(errorSchema), field) {
+			t.Errorf("Error schema lacks %s: %s", field, errorSchema)
+		}
+	}
+
+	// The paginated schema of the spec lists the fields of apiPage
+	spec := openAPISpec((&app{}).apiRoutes())
+	data, _ := json.Marshal(spec["paths"].(map[string]interface{})[apiPrefix+"/documents"])
+	var item struct {
+		Get struct {
+			Responses map[string]struct {
+				Content map[string]struct {
+					Schema struct {
+						Required []string `json:"required"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"get"`
+	}
+	if err := json.Unmarshal(data, &item); err != nil {
+		t.Fatal(err)
+	}
+	required := item.Get.Responses["200"].Content["application/json"].Schema.Required
+	if want := jsonFields(apiPage{}); !reflect.DeepEqual(sortedCopy(required), want) {
+		t.Errorf("paginated schema requires %v, apiPage has %v", required, want)
+	}
+}
+
+// TestAPIResponsesMatchSharedShapes calls every paginated route and a few
+// failing requests and checks the bodies against the page and error shapes.
+func TestAPIResponsesMatchSharedShapes(t *testing.T) {
+	inWorkspace(t, map[string]string{"invoice": "= Invoice\n\nText\n"})
+	runner := weavertest.Local
+	runner.Config = fmt.Sprintf(`
+["sudocu/Authenticator"]
+api_keys = [{user = "olga", sha256 = %q}]
+
+["sudocu/ADocRepository"]
+folder_roles = {"olga" = "owner"}
+`, apiKeyHash("owner-key"))
+
+	runner.Test(t, func(t *testing.T, a *app) {
+		router := mux.NewRouter()
+		router.Use(a.authenticate, a.canonicalizeDocument, a.authorize)
+		a.registerAPI(router)
+		call := func(method string, target string) (int, map[string]json.RawMessage) {
+			req := httptest.NewRequest(method, target, nil)
+			req.Header.Set("X-API-Key", "owner-key")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("%s %s: %v in %q", method, target, err, recorder.Body.String())
+			}
+			return recorder.Code, body
+		}
+
+		for _, route := range a.apiRoutes() {
+			if !route.paginated {
+				continue
+			}
+			target := apiPrefix + strings.ReplaceAll(route.path, "{name}", "invoice")
+			status, body := call(route.method, target)
+			if status != http.StatusOK || !reflect.DeepEqual(keys(body), jsonFields(apiPage{})) {
+				t.Errorf("%s: status %d, fields %v, want a page", target, status, keys(body))
+			}
+
+			status, body = call(route.method, target+"?page=0")
+			if status != http.StatusBadRequest {
+				t.Errorf("%s?page=0: status %d, want 400", target, status)
+			}
+			checkAPIError(t, target+"?page=0", body)
+		}
+
+		for _, target := range []string{apiPrefix + "/nothing", apiPrefix + "/documents/missing"} {
+			status, body := call(http.MethodGet, target)
+			if status != http.StatusNotFound {
+				t.Errorf("%s: status %d, want 404", target, status)
+			}
+			checkAPIError(t, target, body)
+		}
+		status, body := call(http.MethodDelete, apiPrefix+"/documents")
+		if status != http.StatusMethodNotAllowed {
+			t.Errorf("DELETE /documents: status %d, want 405", status)
+		}
+		checkAPIError(t, "DELETE /documents", body)
+	})
+}
+
+func checkAPIError(t *testing.T, name string, body map[string]json.RawMessage) {
+	t.Helper()
+	var detail map[string]json.RawMessage
+	if !reflect.DeepEqual(keys(body), []string{"error"}) || json.Unmarshal(body["error"], &detail) != nil ||
+		!reflect.DeepEqual(keys(detail), jsonFields(apiErrorDetail{})) {
+		t.Errorf("%s: error body %v is not an apiError", name, body)
+	}
+}
+
+// jsonFields returns the sorted JSON names of a struct's fields.
+func jsonFields(v interface{}) []string {
+	var fields []string
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	return sortedCopy(fields)
+}
+
+func keys[V any](m map[string]V) []string {
+	var result []string
+	for key := range m {
+		result = append(result, key)
+	}
+	return sortedCopy(result)
+}
+
+func sortedCopy(values []string) []string {
+	result := append([]string{}, values...)
+	sort.Strings(result)
+	return result
+}
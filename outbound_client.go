@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+var (
+	// ErrRemoteUnavailable is returned when a remote API still fails with a
+	// network error or a retryable status after all retries.
+	ErrRemoteUnavailable = errors.New("remote API is unavailable")
+	// ErrCircuitOpen wraps ErrRemoteUnavailable. It is returned without
+	// calling the API while the circuit breaker is open.
+	ErrCircuitOpen = fmt.Errorf("%w, circuit breaker is open", ErrRemoteUnavailable)
+)
+
+// outboundConfig tunes the calls of a component to a remote API, e.g.
+// `outbound = { timeout_seconds = 60, max_retries = 5 }`.
+type outboundConfig struct {
+	// TimeoutSeconds limits each attempt, 120 by default.
+	TimeoutSeconds int `toml:"timeout_seconds"`
+	// MaxRetries is how often a call is repeated after a network error, a 429
+	// or a 5xx status, 3 by default. A negative value disables retries.
+	MaxRetries int `toml:"max_retries"`
+	// PostsHaveSideEffects is set for APIs whose POST requests change
+	// something. They are then only repeated after a 429 or 503, or when
+	// they could not be sent at all, since the API may have acted on them.
+	// Completions, transcriptions and speech only cost a second call.
+	PostsHaveSideEffects bool `toml:"posts_have_side_effects"`
+	// MaxBackoffSeconds caps the wait between attempts, 30 by default. A
+	// longer Retry-After ends the retries.
+	MaxBackoffSeconds int `toml:"max_backoff_seconds"`
+	// BreakerFailures is the number of failed attempts in a row that opens
+	// the circuit breaker for BreakerCooldownSeconds, 5 and 30 by default.
+	BreakerFailures        int `toml:"breaker_failures"`
+	BreakerCooldownSeconds int `toml:"breaker_cooldown_seconds"`
+}
+
+// outboundClient sends requests to a remote API. Every attempt has its own
+// timeout within the context of the call, failed attempts are retried with
+// exponential backoff and jitter, and a circuit breaker stops calling an API
+// that keeps failing. After the cooldown a single attempt probes the API.
+type outboundClient struct {
+	client      *http.Client
+	logger      *slog.Logger
+	timeout     time.Duration
+	maxRetries  int
+	maxBackoff  time.Duration
+	maxFailures int
+	cooldown    time.Duration
+	// safePosts lets POST requests be retried like idempotent ones.
+	safePosts bool
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newOutboundClient(config outboundConfig, logger *slog.Logger) *outboundClient {
+	orDefault := func(value int, def int) int {
+		if value == 0 {
+			return def
+		}
+		return value
+	}
+	return &outboundClient{
+		client:      &http.Client{},
+		logger:      logger,
+		timeout:     time.Duration(orDefault(config.TimeoutSeconds, 120)) * time.Second,
+		maxRetries:  orDefault(config.MaxRetries, 3),
+		safePosts:   !config.PostsHaveSideEffects,
+		maxBackoff:  time.Duration(orDefault(config.MaxBackoffSeconds, 30)) * time.Second,
+		maxFailures: orDefault(config.BreakerFailures, 5),
+		cooldown:    time.Duration(orDefault(config.BreakerCooldownSeconds, 30)) * time.Second,
+	}
+}
+
+// Do sends a request built with http.NewRequestWithContext. The body of the
+// returned response is already read, so it stays valid after the attempt's
+// timeout. Requests with a body must support GetBody to be retried.
+func (c *outboundClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if err := c.allow(); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, req.URL.Host)
+		}
+
+		resp, sent, err := c.attempt(ctx, req)
+		retry := retryable(req, resp, sent, err, c.safePosts)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		c.record(err != nil || resp.StatusCode >= 500)
+		if !retry && err == nil {
+			return resp, nil
+		}
+
+		wait, ok := c.backoff(attempt, resp)
+		if !retry || !ok || attempt >= c.maxRetries || (req.Body != nil && req.GetBody == nil) {
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrRemoteUnavailable, err)
+			}
+			return nil, fmt.Errorf("%w: %s responded with status %d: %s", ErrRemoteUnavailable, req.URL.Host, resp.StatusCode, readBody(resp))
+		}
+
+		c.logger.Warn("Retrying remote API call", "host", req.URL.Host, "attempt", attempt+1, "wait", wait, "err", err, "status", statusOf(resp))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable tells whether a failed attempt may be repeated. Idempotent
+// requests, and POSTs if safePosts is set, are repeated after any network
+// error or 5xx status. Other requests only when they were not sent, or when
+// the API asks to come back later.
+func retryable(req *http.Request, resp *http.Response, sent bool, err error, safePosts bool) bool {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead ||
+		req.Method == http.MethodPut || req.Method == http.MethodDelete || req.Method == http.MethodOptions ||
+		(req.Method == http.MethodPost && safePosts)
+	if err != nil {
+		return idempotent || !sent
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return true
+	case resp.StatusCode >= 500:
+		return idempotent
+	}
+	return false
+}
+
+// attempt sends the request once and reads the whole response. It also tells
+// whether the request was sent, a timeout after that may leave the request
+// done without an answer.
+func (c *outboundClient) attempt(ctx context.Context, req *http.Request) (*http.Response, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var sent atomic.Bool
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteHeaders: func() { sent.Store(true) },
+	})
+
+	attempt := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false, err
+		}
+		attempt.Body = body
+	}
+
+	resp, err := c.client.Do(attempt)
+	if err != nil {
+		return nil, sent.Load(), fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read response body: %v", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, true, nil
+}
+
+// backoff returns how long to wait before the next attempt. Retry-After is
+// honored, but a longer wait than the maximum is not worth retrying.
+func (c *outboundClient) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, wait <= c.maxBackoff
+		}
+	}
+
+	wait := 500 * time.Millisecond << attempt
+	if wait > c.maxBackoff || wait <= 0 {
+		wait = c.maxBackoff
+	}
+	// Full jitter keeps clients that failed together from retrying together
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1)), true
+}
+
+// retryAfter parses the seconds or HTTP date of a Retry-After header.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(t); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// allow fails while the breaker is open. After the cooldown the breaker is
+// half open: the next attempt is let through as a probe, and the others fail
+// until the probe succeeds or times out. A failed probe opens the breaker
+// again.
+func (c *outboundClient) allow() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.openUntil) {
+		return ErrCircuitOpen
+	}
+	if c.failures >= c.maxFailures {
+		c.openUntil = time.Now().Add(c.timeout)
+	}
+	return nil
+}
+
+// record counts failed attempts in a row. Rate limits do not count, the API
+// is up but busy.
+func (c *outboundClient) record(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !failed {
+		c.failures = 0
+		c.openUntil = time.Time{}
+		return
+	}
+	c.failures++
+	if c.failures >= c.maxFailures {
+		c.openUntil = time.Now().Add(c.cooldown)
+		c.logger.Error("Opened circuit breaker", "failures", c.failures, "cooldown", c.cooldown)
+	}
+}
+
+func readBody(resp *http.Response) string {
+	body, _ := ioutil.ReadAll(resp.Body)
+	return string(body)
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// testOutboundClient returns a client with short waits, so retries and the
+// breaker can be tested in milliseconds.
+func testOutboundClient(config outboundConfig) *outboundClient {
+	c := newOutboundClient(config, slog.New(slog.NewTextHandler(ioutil.Discard, nil)))
+	c.timeout = 200 * time.Millisecond
+	c.maxBackoff = 10 * time.Millisecond
+	c.cooldown = 100 * time.Millisecond
+	return c
+}
+
+// countingServer answers with the handler and counts the requests.
+func countingServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, n int32)) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, atomic.AddInt32(&requests, 1))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func postRequest(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader([]byte(`{"prompt":"hi"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestOutboundClientRetryAfterSeconds(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request, n int32) {
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	})
+	c := testOutboundClient(outboundConfig{})
+	c.maxBackoff = 2 * time.Second
+
+	start := time.Now()
+	resp, err := c.Do(postRequest(t, server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readBody(resp); body != `{"prompt":"hi"}` {
+		t.Errorf("retry sent body %q", body)
+	}
+	if atomic.LoadInt32(requests) != 2 {
+		t.Errorf("got %d requests, want 2", atomic.LoadInt32(requests))
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Errorf("waited %v, want the second of Retry-After", waited)
+	}
+}
+
+func TestOutboundClientRetryAfterDate(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request, n int32) {
+		if n == 1 {
+			w.Header().Set("Retry-After", time.Now().Add(time.Second).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+	c := testOutboundClient(outboundConfig{})
+	c.maxBackoff = 2 * time.Second
+
+	if _, err := c.Do(postRequest(t, server.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(requests) != 2 {
+		t.Errorf("got %d requests, want 2", atomic.LoadInt32(requests))
+	}
+}
+
+func TestOutboundClientRetryAfterTooLong(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c := testOutboundClient(outboundConfig{})
+
+	if _, err := c.Do(postRequest(t, server.URL)); !errors.Is(err, ErrRemoteUnavailable) {
+		t.Errorf("got %v, want ErrRemoteUnavailable", err)
+	}
+	if atomic.LoadInt32(requests) != 1 {
+		t.Errorf("got %d requests, want 1", atomic.LoadInt32(requests))
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"0":                             0,
+		"1":                             time.Second,
+		"30":                            30 * time.Second,
+		"Wed, 21 Oct 2015 07:28:00 GMT": 0,
+	}
+	for value, want := range tests {
+		if got, ok := retryAfter(value); !ok || got != want {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v", value, got, ok, want)
+		}
+	}
+	for _, value := range []string{"", "-1", "soon"} {
+		if _, ok := retryAfter(value); ok {
+			t.Errorf("retryAfter(%q) accepted", value)
+		}
+	}
+	if got, ok := retryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); !ok || got < 58*time.Second || got > time.Minute {
+		t.Errorf("retryAfter of a date in a minute = %v, %v", got, ok)
+	}
+}
+
+func TestOutboundClientStopsAtMaxRetries(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	c := testOutboundClient(outboundConfig{MaxRetries: 2, BreakerFailures: 10})
+
+	if _, err := c.Do(postRequest(t, server.URL)); !errors.Is(err, ErrRemoteUnavailable) {
+		t.Errorf("got %v, want ErrRemoteUnavailable", err)
+	}
+	if atomic.LoadInt32(requests) != 3 {
+		t.Errorf("got %d requests, want 1 and 2 retries", atomic.LoadInt32(requests))
+	}
+}
+
+func TestOutboundClientRetriesServerErrors(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request, n int32) {
+		if n%3 != 0 {
+			w.WriteHeader([]int{http.StatusInternalServerError, http.StatusBadGateway}[n%3-1])
+		}
+	})
+	c := testOutboundClient(outboundConfig{MaxRetries: 2})
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	if resp, err := c.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("got %v, %v", statusOf(resp), err)
+	}
+	if atomic.LoadInt32(requests) != 3 {
+		t.Errorf("got %d requests, want 3", atomic.LoadInt32(requests))
+	}
+
+	// Completions and transcriptions are POSTs that change nothing
+	atomic.StoreInt32(requests, 0)
+	if resp, err := c.Do(postRequest(t, server.URL)); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("got %v, %v", statusOf(resp), err)
+	}
+	if atomic.LoadInt32(requests) != 3 {
+		t.Errorf("POST got %d requests, want 3", atomic.LoadInt32(requests))
+	}
+}
+
+func TestOutboundClientKeepsPostsWithSideEffects(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request, n int32) {
+		if n < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+	c := testOutboundClient(outboundConfig{MaxRetries: 2, PostsHaveSideEffects: true})
+
+	// The POST may have been carried out, a 502 does not tell
+	if resp, err := c.Do(postRequest(t, server.URL)); err != nil || resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("got %v, %v, want the 502", statusOf(resp), err)
+	}
+	if atomic.LoadInt32(requests) != 1 {
+		t.Errorf("POST got %d requests, want 1", atomic.LoadInt32(requests))
+	}
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	if resp, err := c.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("GET got %v, %v", statusOf(resp), err)
+	}
+}
+
+func TestOutboundClientTimeout(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request, n int32) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	c := testOutboundClient(outboundConfig{MaxRetries: 3, BreakerFailures: 10})
+	c.timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	if _, err := c.Do(postRequest(t, server.URL)); !errors.Is(err, ErrRemoteUnavailable) {
+		t.Errorf("got %v, want ErrRemoteUnavailable", err)
+	}
+	if waited := time.Since(start); waited > 800*time.Millisecond {
+		t.Errorf("slow responses took %v, want the timeouts", waited)
+	}
+	if atomic.LoadInt32(requests) != 4 {
+		t.Errorf("got %d requests, want 1 and 3 retries", atomic.LoadInt32(requests))
+	}
+
+	// A POST with side effects was sent, so it is not repeated
+	atomic.StoreInt32(requests, 0)
+	c = testOutboundClient(outboundConfig{MaxRetries: 3, PostsHaveSideEffects: true})
+	c.timeout = 50 * time.Millisecond
+	if _, err := c.Do(postRequest(t, server.URL)); !errors.Is(err, ErrRemoteUnavailable) {
+		t.Errorf("got %v, want ErrRemoteUnavailable", err)
+	}
+	if atomic.LoadInt32(requests) != 1 {
+		t.Errorf("got %d requests, want 1", atomic.LoadInt32(requests))
+	}
+}
+
+func TestOutboundClientRetriesUnsentPosts(t *testing.T) {
+	// Nothing listens on the address of a closed server
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	c := testOutboundClient(outboundConfig{MaxRetries: 2, BreakerFailures: 10, PostsHaveSideEffects: true})
+
+	if _, err := c.Do(postRequest(t, server.URL)); !errors.Is(err, ErrRemoteUnavailable) {
+		t.Errorf("got %v, want ErrRemoteUnavailable", err)
+	}
+	if c.failures != 3 {
+		t.Errorf("got %d attempts, want 3", c.failures)
+	}
+}
+
+func TestOutboundClientCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	server, requests := countingServer(t, func(w http.ResponseWriter, r *http.Request, n int32) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	c := testOutboundClient(outboundConfig{MaxRetries: -1, BreakerFailures: 2})
+	get := func() error {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		_, err := c.Do(req)
+		return err
+	}
+
+	// Two failures open the breaker, the API is not called anymore
+	get()
+	get()
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got %v, want ErrCircuitOpen", err)
+	}
+	if atomic.LoadInt32(requests) != 2 {
+		t.Errorf("got %d requests while open, want 2", atomic.LoadInt32(requests))
+	}
+
+	// After the cooldown a probe goes through and fails, which opens the
+	// breaker again
+	time.Sleep(c.cooldown)
+	if err := get(); errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrRemoteUnavailable) {
+		t.Errorf("probe got %v, want ErrRemoteUnavailable", err)
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got %v after a failed probe, want ErrCircuitOpen", err)
+	}
+	if atomic.LoadInt32(requests) != 3 {
+		t.Errorf("got %d requests, want 3", atomic.LoadInt32(requests))
+	}
+
+	// A successful probe closes the breaker
+	time.Sleep(c.cooldown)
+	failing.Store(false)
+	if err := get(); err != nil {
+		t.Errorf("probe got %v", err)
+	}
+	if err := get(); err != nil {
+		t.Errorf("got %v after a successful probe", err)
+	}
+}
+
+func TestOutboundClientHalfOpenLetsOneProbeThrough(t *testing.T) {
+	c := testOutboundClient(outboundConfig{BreakerFailures: 1})
+	c.record(true)
+	if err := c.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v, want ErrCircuitOpen", err)
+	}
+
+	time.Sleep(c.cooldown)
+	if err := c.allow(); err != nil {
+		t.Fatalf("probe got %v", err)
+	}
+	if err := c.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second call during the probe got %v, want ErrCircuitOpen", err)
+	}
+	c.record(false)
+	if err := c.allow(); err != nil {
+		t.Errorf("got %v after the probe succeeded", err)
+	}
+}
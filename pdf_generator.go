@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os/exec"
 
 	"github.com/ServiceWeaver/weaver"
@@ -10,16 +11,66 @@ import (
 
 type PDFGenerator interface {
 	GeneratePDF(context.Context, []byte) ([]byte, error)
+	GuardMarkup(context.Context, []byte) ([]byte, []BlockedDirective, error)
+}
+
+type pdfGeneratorConfig struct {
+	// SafeMode of asciidoctor, "secure" by default, which ignores includes
+	// altogether. "safe" allows includes below the allowed roots.
+	SafeMode string `toml:"safe_mode"`
+	// AllowedRoots are the folders includes and images may be read from,
+	// relative to the working directory. Only "adocs" by default. Rendering
+	// cannot read above adocs, so other roots must be below it.
+	AllowedRoots []string `toml:"allowed_roots"`
 }
 
 // Implementation of the PDFGenerator component.
 type pdfGenerator struct {
 	weaver.Implements[PDFGenerator]
+	weaver.WithConfig[pdfGeneratorConfig]
+}
+
+func (g *pdfGenerator) Init(context.Context) error {
+	switch g.Config().SafeMode {
+	case "", "secure", "safe":
+		return nil
+	default:
+		return fmt.Errorf("invalid safe_mode %q, expected secure or safe", g.Config().SafeMode)
+	}
+}
+
+func (g *pdfGenerator) roots() []string {
+	if len(g.Config().AllowedRoots) == 0 {
+		return []string{adocsDirName}
+	}
+	return g.Config().AllowedRoots
+}
+
+// GuardMarkup removes the directives that would read files outside of the
+// allowed roots or remote resources, and reports which ones were blocked.
+func (g *pdfGenerator) GuardMarkup(_ context.Context, content []byte) ([]byte, []BlockedDirective, error) {
+	guarded, blocked := guardMarkup(content, g.roots())
+	return guarded, blocked, nil
 }
 
 func (g *pdfGenerator) GeneratePDF(_ context.Context, content []byte) ([]byte, error) {
-	// Generate PDF using AsciidoctorJ and a shell command
-	cmd := exec.Command("asciidoctor-pdf", "-", "--theme", "default-sans")
+	// Markup that was not guarded when it was saved is checked again here
+	content, blocked := guardMarkup(content, g.roots())
+	for _, directive := range blocked {
+		g.Logger().Warn("Blocked directive while rendering", "line", directive.Line, "directive", directive.Directive, "reason", directive.Reason)
+	}
+
+	safeMode := g.Config().SafeMode
+	if safeMode == "" {
+		safeMode = "secure"
+	}
+
+	// Generate PDF using AsciidoctorJ and a shell command. Attributes set on
+	// the command line cannot be overridden by the document. Files are read
+	// relative to adocs, so the session secrets and archives in work stay
+	// out of reach.
+	cmd := exec.Command("asciidoctor-pdf", "-", "--theme", "default-sans",
+		"--safe-mode", safeMode, "--base-dir", adocsDirName, "-a", "allow-uri-read!")
 	cmd.Stdin = bytes.NewReader(content)
 	var pdfContent bytes.Buffer
 	cmd.Stdout = &pdfContent
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// promptTemplateRequest creates or updates a template.
+type promptTemplateRequest struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+}
+
+// dateLayouts write {{today}} the way documents in a language show dates.
+// Other languages get ISO 8601.
+var dateLayouts = map[string]string{
+	"de": "02.01.2006",
+	"es": "02/01/2006",
+	"fr": "02/01/2006",
+	"it": "02/01/2006",
+	"nl": "02-01-2006",
+	"pl": "02.01.2006",
+}
+
+// formatToday returns today's date in the format of a document's :lang:.
+func formatToday(markup []byte) string {
+	layout, ok := dateLayouts[documentLanguage(markup)]
+	if !ok {
+		layout = "2006-01-02"
+	}
+	return time.Now().Format(layout)
+}
+
+// editPrompt returns the prompt of a change, which is either typed or
+// expanded from a template. Besides the values of the request, templates can
+// use the built-in placeholders {{today}}, {{document}} and {{user}}.
+func (a *app) editPrompt(r *http.Request, fileName string, prompt string, templateID string, values map[string]string) (string, error) {
+	if templateID == "" {
+		return prompt, nil
+	}
+	if prompt != "" {
+		return "", fmt.Errorf("%w: send either a prompt or a template", errInvalidRequest)
+	}
+
+	template, err := a.promptTemplates.Get().Get(r.Context(), templateID)
+	if err != nil {
+		return "", err
+	}
+	markup, err := a.aDocRepository.Get().ReadFile(r.Context(), fileName)
+	if err != nil {
+		return "", err
+	}
+
+	filled := map[string]string{
+		"today":    formatToday(markup),
+		"document": fileName,
+		"user":     requestUser(r).Name,
+	}
+	for name, value := range values {
+		if !builtinPlaceholders[name] {
+			filled[name] = value
+		}
+	}
+	return expandPromptTemplate(template, filled)
+}
+
+// savePromptTemplate creates a template, or updates the one with the id.
+// Templates are shared by everyone, so only owners of all documents manage
+// them.
+func (a *app) savePromptTemplate(r *http.Request, id string) (PromptTemplate, error) {
+	if err := a.authorizeRequest(r, "", RoleOwner); err != nil {
+		return PromptTemplate{}, err
+	}
+
+	var request promptTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return PromptTemplate{}, fmt.Errorf("%w: %v", errInvalidRequest, err)
+	}
+	return a.promptTemplates.Get().Save(r.Context(), PromptTemplate{
+		ID:        id,
+		Name:      request.Name,
+		Prompt:    request.Prompt,
+		UpdatedBy: requestUser(r).Name,
+	})
+}
+
+func (a *app) deletePromptTemplate(r *http.Request, id string) (PromptTemplate, error) {
+	if err := a.authorizeRequest(r, "", RoleOwner); err != nil {
+		return PromptTemplate{}, err
+	}
+
+	template, err := a.promptTemplates.Get().Get(r.Context(), id)
+	if err != nil {
+		return PromptTemplate{}, err
+	}
+	return template, a.promptTemplates.Get().Delete(r.Context(), id)
+}
+
+func (a *app) registerPromptTemplates(router *mux.Router) {
+	// The quick actions of the editor
+	router.HandleFunc("/prompt-templates", func(w http.ResponseWriter, r *http.Request) {
+		templates, err := a.promptTemplates.Get().List(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			a.Logger().Warn(err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(templates); err != nil {
+			a.Logger().Warn("Error writing response:", err)
+		}
+	}).Methods(http.MethodGet)
+}
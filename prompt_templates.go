@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+const promptTemplatesFileName = "prompt_templates.json"
+
+var ErrNoSuchTemplate = errors.New("prompt template does not exist")
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// builtinPlaceholders are filled in by the server and need no value.
+var builtinPlaceholders = map[string]bool{"today": true, "document": true, "user": true}
+
+type PromptTemplates interface {
+	List(ctx context.Context) ([]PromptTemplate, error)
+	Get(ctx context.Context, id string) (PromptTemplate, error)
+	Save(ctx context.Context, template PromptTemplate) (PromptTemplate, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// PromptTemplate is a prompt teams use again and again, shown as a quick
+// action in the editor. The prompt may contain placeholders like {{item}}.
+type PromptTemplate struct {
+	weaver.AutoMarshal
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+	// Placeholders lists the values the user has to fill in, it is derived
+	// from the prompt.
+	Placeholders []string  `json:"placeholders"`
+	UpdatedBy    string    `json:"updatedBy,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// defaultPromptTemplates are offered until the first template is saved.
+var defaultPromptTemplates = []PromptTemplate{
+	{ID: "translate-english", Name: "Translate to English", Prompt: "Translate the whole document to English."},
+	{ID: "make-formal", Name: "Make formal", Prompt: "Rewrite the text in a formal, polite tone without changing its meaning."},
+	{ID: "add-line-item", Name: "Add a line item", Prompt: "Add a line item for {{quantity}} x {{item}} at {{price}} each."},
+	{ID: "update-date", Name: "Update the date", Prompt: "Update the date of the document to {{today}}."},
+}
+
+// Implementation of the PromptTemplates component.
+type promptTemplates struct {
+	weaver.Implements[PromptTemplates]
+	mu sync.Mutex
+}
+
+func (p *promptTemplates) path() string {
+	return filepath.Join(workDirName, promptTemplatesFileName)
+}
+
+// List returns the templates sorted by name.
+func (p *promptTemplates) List(ctx context.Context) ([]PromptTemplate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	templates, err := p.readTemplates()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return strings.ToLower(templates[i].Name) < strings.ToLower(templates[j].Name)
+	})
+	return templates, nil
+}
+
+func (p *promptTemplates) Get(ctx context.Context, id string) (PromptTemplate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	templates, err := p.readTemplates()
+	if err != nil {
+		return PromptTemplate{}, err
+	}
+	for _, template := range templates {
+		if template.ID == id {
+			return template, nil
+		}
+	}
+	return PromptTemplate{}, fmt.Errorf("%w: %s", ErrNoSuchTemplate, id)
+}
+
+// Save creates a template without ID and replaces the one with the ID
+// otherwise.
+func (p *promptTemplates) Save(ctx context.Context, template PromptTemplate) (PromptTemplate, error) {
+	template.Name = strings.TrimSpace(template.Name)
+	template.Prompt = strings.TrimSpace(template.Prompt)
+	if template.Name == "" || len(template.Name) > 50 {
+		return PromptTemplate{}, fmt.Errorf("%w: name must have 1 to 50 characters", errInvalidRequest)
+	}
+	if template.Prompt == "" {
+		return PromptTemplate{}, fmt.Errorf("%w: prompt is empty", errInvalidRequest)
+	}
+	template.Placeholders = templatePlaceholders(template.Prompt)
+	template.UpdatedAt = time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	templates, err := p.readTemplates()
+	if err != nil {
+		return PromptTemplate{}, err
+	}
+
+	if template.ID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return PromptTemplate{}, err
+		}
+		template.ID = hex.EncodeToString(id)
+		templates = append(templates, template)
+	} else {
+		found := false
+		for i := range templates {
+			if templates[i].ID == template.ID {
+				templates[i], found = template, true
+			}
+		}
+		if !found {
+			return PromptTemplate{}, fmt.Errorf("%w: %s", ErrNoSuchTemplate, template.ID)
+		}
+	}
+
+	if err := p.writeTemplates(templates); err != nil {
+		return PromptTemplate{}, err
+	}
+	p.Logger().Info("Saved prompt template", "id", template.ID, "name", template.Name, "user", template.UpdatedBy)
+	return template, nil
+}
+
+func (p *promptTemplates) Delete(ctx context.Context, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	templates, err := p.readTemplates()
+	if err != nil {
+		return err
+	}
+	for i, template := range templates {
+		if template.ID == id {
+			return p.writeTemplates(append(templates[:i], templates[i+1:]...))
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNoSuchTemplate, id)
+}
+
+func (p *promptTemplates) readTemplates() ([]PromptTemplate, error) {
+	data, err := ioutil.ReadFile(p.path())
+	if os.IsNotExist(err) {
+		templates := make([]PromptTemplate, len(defaultPromptTemplates))
+		for i, template := range defaultPromptTemplates {
+			template.Placeholders = templatePlaceholders(template.Prompt)
+			templates[i] = template
+		}
+		return templates, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var templates []PromptTemplate
+	err = json.Unmarshal(data, &templates)
+	return templates, err
+}
+
+func (p *promptTemplates) writeTemplates(templates []PromptTemplate) error {
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(workDirName, 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so a crash cannot truncate the list
+	if err := ioutil.WriteFile(p.path()+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(p.path()+".tmp", p.path())
+}
+
+// templatePlaceholders returns the placeholders of a prompt that are not
+// built in, in order of appearance.
+func templatePlaceholders(prompt string) []string {
+	placeholders := []string{}
+	seen := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(prompt, -1) {
+		if name := match[1]; !builtinPlaceholders[name] && !seen[name] {
+			placeholders = append(placeholders, name)
+			seen[name] = true
+		}
+	}
+	return placeholders
+}
+
+// expandPromptTemplate fills in the placeholders of a template. Every
+// placeholder needs a value.
+func expandPromptTemplate(template PromptTemplate, values map[string]string) (string, error) {
+	var missing []string
+	prompt := placeholderPattern.ReplaceAllStringFunc(template.Prompt, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := values[name]
+		if !ok || strings.TrimSpace(value) == "" {
+			missing = append(missing, name)
+		}
+		return strings.TrimSpace(value)
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: template %s needs a value for %s", errInvalidRequest, template.Name, strings.Join(missing, ", "))
+	}
+	return prompt, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatToday(t *testing.T) {
+	tests := map[string]string{
+		"= Rechnung\n:lang: de\n":    "02.01.2006",
+		"= Rechnung\n:lang: de-AT\n": "02.01.2006",
+		"= Facture\n:lang: fr\n":     "02/01/2006",
+		"= Invoice\n:lang: en\n":     "2006-01-02",
+		"= Invoice\n":                "2006-01-02",
+	}
+	for markup, layout := range tests {
+		if got, want := formatToday([]byte(markup)), time.Now().Format(layout); got != want {
+			t.Errorf("formatToday(%q) = %q, want %q", markup, got, want)
+		}
+	}
+}
+
+func TestExpandPromptTemplate(t *testing.T) {
+	template := PromptTemplate{Name: "Update the date", Prompt: "Set the date to {{today}} and add {{ item }}."}
+	prompt, err := expandPromptTemplate(template, map[string]string{"today": "19.10.2026", "item": " travel "})
+	if err != nil || prompt != "Set the date to 19.10.2026 and add travel." {
+		t.Errorf("got %q, %v", prompt, err)
+	}
+	if _, err := expandPromptTemplate(template, map[string]string{"today": "19.10.2026"}); err == nil {
+		t.Errorf("expanded a template with a missing value")
+	}
+}
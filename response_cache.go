@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// responseCache keeps GPT answers in work/cache, so repeating a prompt on the
+// same markup neither pays for nor waits for a second completion.
+type responseCache struct {
+	ttl time.Duration
+}
+
+type cachedResponse struct {
+	Time    time.Time `json:"time"`
+	Content string    `json:"content"`
+}
+
+func (c *responseCache) dir() string {
+	return filepath.Join(workDirName, "cache")
+}
+
+// key hashes everything that shapes the answer. The messages themselves are
+// not used, their document tags change with every request.
+func (c *responseCache) key(request ChatGPTRequest, oldMarkup string, prompt string, part string) string {
+	data, _ := json.Marshal(struct {
+		Request      ChatGPTRequest
+		Instructions string
+		Markup       string
+		Prompt       string
+		Part         string
+	}{request, changeInstructions + partInstructions, oldMarkup, prompt, part})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns a cached answer that has not expired yet.
+func (c *responseCache) get(key string) (string, bool) {
+	path := filepath.Join(c.dir(), key+".json")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	var response cachedResponse
+	if err := json.Unmarshal(data, &response); err != nil || time.Since(response.Time) > c.ttl {
+		os.Remove(path)
+		return "", false
+	}
+	return response.Content, true
+}
+
+func (c *responseCache) put(key string, content string) error {
+	data, err := json.Marshal(cachedResponse{Time: time.Now(), Content: content})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir(), 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(c.dir(), key+".json")
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// sweep removes the expired answers.
+func (c *responseCache) sweep() {
+	files, err := ioutil.ReadDir(c.dir())
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if time.Since(file.ModTime()) > c.ttl {
+			os.Remove(filepath.Join(c.dir(), file.Name()))
+		}
+	}
+}
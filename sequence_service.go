@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+const sequencesFileName = "sequences.json"
+
+// sequencePlaceholderPattern matches attribute values like `{sequence:INV}`
+// that are replaced by the next number of the INV sequence.
+var sequencePlaceholderPattern = regexp.MustCompile(`\{sequence:([A-Za-z0-9_-]+)\}`)
+
+type SequenceService interface {
+	Next(ctx context.Context, prefix string, document string, reservation string) (string, error)
+	FillPlaceholders(ctx context.Context, document string, markup []byte, finalize bool) ([]byte, string, error)
+	Commit(ctx context.Context, reservation string) error
+	Release(ctx context.Context, reservation string) error
+}
+
+// SequenceAssignment records which document received which number.
+type SequenceAssignment struct {
+	Number     string    `json:"number"`
+	Prefix     string    `json:"prefix"`
+	Year       int       `json:"year"`
+	Value      int       `json:"value"`
+	Document   string    `json:"document"`
+	AssignedAt time.Time `json:"assignedAt"`
+	// Reservations lists the unsaved changes that reserved the number. It is
+	// empty once a change that uses the number was saved.
+	Reservations []string `json:"reservations,omitempty"`
+}
+
+type sequenceState struct {
+	// Counters maps "prefix/year" to the last number handed out.
+	Counters map[string]int `json:"counters"`
+	// Released maps "prefix/year" to numbers below the counter whose
+	// reservation was released. They are handed out again first.
+	Released    map[string][]int     `json:"released,omitempty"`
+	Assignments []SequenceAssignment `json:"assignments"`
+}
+
+type sequenceConfig struct {
+	// AssignOn is either "save" (default) to fill placeholders whenever a
+	// variant is saved, or "finalize" to only number finalized documents.
+	AssignOn string `toml:"assign_on"`
+}
+
+// Implementation of the SequenceService component.
+//
+// The counters are kept in a single file guarded by a mutex, so numbers are
+// only gap-free as long as one replica of the component is running.
+type sequenceService struct {
+	weaver.Implements[SequenceService]
+	weaver.WithConfig[sequenceConfig]
+	mu sync.Mutex
+}
+
+func (s *sequenceService) Init(context.Context) error {
+	switch s.Config().AssignOn {
+	case "", "save", "finalize":
+		return nil
+	default:
+		return fmt.Errorf("invalid assign_on %q, expected save or finalize", s.Config().AssignOn)
+	}
+}
+
+// Next reserves the next number of the prefix's sequence for the current
+// year on behalf of a change. A document that already received a number of
+// that sequence gets the same number again, so a number is never handed out
+// twice. The reservation holds until Commit or Release is called with it, an
+// empty reservation assigns the number for good.
+func (s *sequenceService) Next(ctx context.Context, prefix string, document string, reservation string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	year := time.Now().Year()
+	for i, assignment := range state.Assignments {
+		if assignment.Prefix == prefix && assignment.Year == year && assignment.Document == document {
+			if len(assignment.Reservations) == 0 || containsString(assignment.Reservations, reservation) {
+				return assignment.Number, nil
+			}
+			if reservation == "" {
+				state.Assignments[i].Reservations = nil
+			} else {
+				state.Assignments[i].Reservations = append(assignment.Reservations, reservation)
+			}
+			return assignment.Number, s.save(state)
+		}
+	}
+
+	// Released numbers are reused lowest first, so no number is skipped
+	key := fmt.Sprintf("%s/%d", prefix, year)
+	value := state.Counters[key] + 1
+	if released := state.Released[key]; len(released) > 0 {
+		sort.Ints(released)
+		value, state.Released[key] = released[0], released[1:]
+	} else {
+		state.Counters[key] = value
+	}
+	assignment := SequenceAssignment{
+		Number:     fmt.Sprintf("%s-%d-%03d", prefix, year, value),
+		Prefix:     prefix,
+		Year:       year,
+		Value:      value,
+		Document:   document,
+		AssignedAt: time.Now(),
+	}
+	if reservation != "" {
+		assignment.Reservations = []string{reservation}
+	}
+	state.Assignments = append(state.Assignments, assignment)
+
+	if err := s.save(state); err != nil {
+		return "", err
+	}
+
+	s.Logger().Info("Reserved sequence number", "number", assignment.Number, "document", document)
+
+	return assignment.Number, nil
+}
+
+// Commit keeps the numbers of a reservation for good. It is called once the
+// markup with the numbers was saved.
+func (s *sequenceService) Commit(ctx context.Context, reservation string) error {
+	if reservation == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	for i, assignment := range state.Assignments {
+		if containsString(assignment.Reservations, reservation) {
+			state.Assignments[i].Reservations = nil
+			committed = true
+			s.Logger().Info("Assigned sequence number", "number", assignment.Number, "document", assignment.Document)
+		}
+	}
+	if !committed {
+		return nil
+	}
+	return s.save(state)
+}
+
+// Release gives back the numbers of a reservation whose change failed. A
+// number is only handed out again when no other unsaved change holds it and
+// none was saved with it.
+func (s *sequenceService) Release(ctx context.Context, reservation string) error {
+	if reservation == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	var kept []SequenceAssignment
+	released := false
+	for _, assignment := range state.Assignments {
+		if !containsString(assignment.Reservations, reservation) {
+			kept = append(kept, assignment)
+			continue
+		}
+		released = true
+		if assignment.Reservations = removeString(assignment.Reservations, reservation); len(assignment.Reservations) > 0 {
+			kept = append(kept, assignment)
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%d", assignment.Prefix, assignment.Year)
+		if state.Released == nil {
+			state.Released = map[string][]int{}
+		}
+		state.Released[key] = append(state.Released[key], assignment.Value)
+		s.Logger().Info("Released sequence number", "number", assignment.Number, "document", assignment.Document)
+	}
+	if !released {
+		return nil
+	}
+
+	// Released numbers at the end of a sequence lower its counter instead
+	for key, values := range state.Released {
+		sort.Ints(values)
+		for len(values) > 0 && values[len(values)-1] == state.Counters[key] {
+			values = values[:len(values)-1]
+			state.Counters[key]--
+		}
+		if len(values) == 0 {
+			delete(state.Released, key)
+		} else {
+			state.Released[key] = values
+		}
+	}
+
+	state.Assignments = kept
+	return s.save(state)
+}
+
+// FillPlaceholders replaces every `{sequence:PREFIX}` header attribute value
+// with the document's number of that sequence. Unless finalize is set, this
+// is a no-op when numbers are only assigned on finalization. New numbers are
+// reserved for the change, the caller commits or releases the returned
+// reservation once the markup is saved or the change failed. It is empty if
+// there were no placeholders.
+func (s *sequenceService) FillPlaceholders(ctx context.Context, document string, markup []byte, finalize bool) ([]byte, string, error) {
+	if !finalize && s.Config().AssignOn == "finalize" {
+		return markup, "", nil
+	}
+
+	values := make(map[string]string)
+	reservation := ""
+	for _, attribute := range parseHeaderAttributes(markup) {
+		match := sequencePlaceholderPattern.FindStringSubmatch(attribute.Value)
+		if match == nil {
+			continue
+		}
+
+		if reservation == "" {
+			id := make([]byte, 8)
+			if _, err := rand.Read(id); err != nil {
+				return nil, "", err
+			}
+			reservation = hex.EncodeToString(id)
+		}
+		number, err := s.Next(ctx, match[1], document, reservation)
+		if err != nil {
+			if releaseErr := s.Release(ctx, reservation); releaseErr != nil {
+				s.Logger().Warn("Failed to release sequence numbers", "document", document, "err", releaseErr)
+			}
+			return nil, "", err
+		}
+		values[attribute.Name] = sequencePlaceholderPattern.ReplaceAllLiteralString(attribute.Value, number)
+	}
+
+	if len(values) == 0 {
+		return markup, "", nil
+	}
+	return setHeaderAttributes(markup, values), reservation, nil
+}
+
+func (s *sequenceService) load() (*sequenceState, error) {
+	state := &sequenceState{Counters: map[string]int{}}
+
+	data, err := ioutil.ReadFile(filepath.Join(workDirName, sequencesFileName))
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Counters == nil {
+		state.Counters = map[string]int{}
+	}
+	return state, nil
+}
+
+// save writes the state to a temporary file first, so a crash can never
+// leave a half written sequences file behind.
+func (s *sequenceService) save(state *sequenceState) error {
+	if err := os.MkdirAll(workDirName, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(workDirName, sequencesFileName)
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
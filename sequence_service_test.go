@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+// inWorkspace runs a test in an empty folder with the given documents, as
+// the components keep their data relative to the working directory.
+func inWorkspace(t *testing.T, documents map[string]string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(adocsDirName, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, markup := range documents {
+		if err := os.WriteFile(filepath.Join(adocsDirName, name+".adoc"), []byte(markup), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sequenceNumber(value int) string {
+	return fmt.Sprintf("INV-%d-%03d", time.Now().Year(), value)
+}
+
+func TestSequenceReleasedNumbersAreReused(t *testing.T) {
+	inWorkspace(t, nil)
+	weavertest.Local.Test(t, func(t *testing.T, sequences SequenceService) {
+		ctx := context.Background()
+		next := func(document string, want int) {
+			t.Helper()
+			if got, err := sequences.Next(ctx, "INV", document, "change-"+document); err != nil || got != sequenceNumber(want) {
+				t.Fatalf("Next(%s) = %q, %v, want %q", document, got, err, sequenceNumber(want))
+			}
+		}
+
+		next("a", 1)
+		next("b", 2)
+		next("c", 3)
+		if err := sequences.Commit(ctx, "change-a"); err != nil {
+			t.Fatal(err)
+		}
+		if err := sequences.Commit(ctx, "change-c"); err != nil {
+			t.Fatal(err)
+		}
+
+		// A released number in the middle is handed out again
+		if err := sequences.Release(ctx, "change-b"); err != nil {
+			t.Fatal(err)
+		}
+		next("d", 2)
+
+		// A released number at the end lowers the counter
+		if err := sequences.Release(ctx, "change-d"); err != nil {
+			t.Fatal(err)
+		}
+		next("e", 2)
+		next("f", 4)
+
+		// Committed numbers stay, even when released afterwards
+		if err := sequences.Release(ctx, "change-a"); err != nil {
+			t.Fatal(err)
+		}
+		next("a", 1)
+		next("g", 5)
+	})
+}
+
+func TestSequenceSharedReservation(t *testing.T) {
+	inWorkspace(t, nil)
+	weavertest.Local.Test(t, func(t *testing.T, sequences SequenceService) {
+		ctx := context.Background()
+
+		// Two changes of a document at the same time share its number, a
+		// failing one does not take it from the other
+		first, _ := sequences.Next(ctx, "INV", "a", "first")
+		second, _ := sequences.Next(ctx, "INV", "a", "second")
+		if first != second {
+			t.Fatalf("got %q and %q for the same document", first, second)
+		}
+		if err := sequences.Release(ctx, "first"); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := sequences.Next(ctx, "INV", "b", "third"); got != sequenceNumber(2) {
+			t.Errorf("got %q while a still holds %s", got, first)
+		}
+
+		// Once the last change holding it is released, the number is free
+		if err := sequences.Release(ctx, "second"); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := sequences.Next(ctx, "INV", "c", "fourth"); got != sequenceNumber(1) {
+			t.Errorf("got %q, want the released %s", got, first)
+		}
+	})
+}
+
+func TestSequenceChangesWithoutPlaceholdersKeepOthersReservations(t *testing.T) {
+	inWorkspace(t, nil)
+	weavertest.Local.Test(t, func(t *testing.T, sequences SequenceService) {
+		ctx := context.Background()
+		template := []byte("= Invoice\n:invoice_number: {sequence:INV}\n\nText\n")
+
+		// One change of the document reserves a number, a concurrent one
+		// has no placeholder left and reserves nothing
+		numbered, reservation, err := sequences.FillPlaceholders(ctx, "a", template, false)
+		if err != nil || reservation == "" {
+			t.Fatalf("FillPlaceholders = %q, %v", reservation, err)
+		}
+		unchanged, none, err := sequences.FillPlaceholders(ctx, "a", numbered, false)
+		if err != nil || none != "" || string(unchanged) != string(numbered) {
+			t.Fatalf("FillPlaceholders of numbered markup = %q, %v", none, err)
+		}
+
+		// Neither its failure nor its success settles the other reservation
+		if err := sequences.Release(ctx, none); err != nil {
+			t.Fatal(err)
+		}
+		if err := sequences.Commit(ctx, none); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := sequences.Next(ctx, "INV", "b", "other"); got != sequenceNumber(2) {
+			t.Errorf("got %s while a still reserves %s", got, sequenceNumber(1))
+		}
+		if err := sequences.Release(ctx, reservation); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := sequences.Next(ctx, "INV", "c", "another"); got != sequenceNumber(1) {
+			t.Errorf("got %s, want the released %s", got, sequenceNumber(1))
+		}
+	})
+}
+
+func TestSequenceNumberUnchangedWhenSaveFails(t *testing.T) {
+	inWorkspace(t, map[string]string{
+		"locked":  "= Locked\n:invoice_number: {sequence:INV}\n\nText\n",
+		"invoice": "= Invoice\n:invoice_number: {sequence:INV}\n\nText\n",
+	})
+	weavertest.Local.Test(t, func(t *testing.T, a *app) {
+		ctx := context.Background()
+		if err := os.MkdirAll(workDirName, 0755); err != nil {
+			t.Fatal(err)
+		}
+		name, _ := parseDocumentName("locked")
+		if err := os.WriteFile(name.workPath(".state.json"), []byte(`{"status":"final"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		change := []Attribute{{Name: "note", Value: "paid"}}
+		if _, err := a.setAttributes(ctx, "locked", change, ""); !errors.Is(err, ErrDocumentLocked) {
+			t.Fatalf("saving a locked document returned %v, want ErrDocumentLocked", err)
+		}
+
+		attributes, err := a.setAttributes(ctx, "invoice", change, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, attribute := range attributes {
+			if attribute.Name == "invoice_number" && attribute.Value != sequenceNumber(1) {
+				t.Errorf("invoice got %s after a failed save, want %s", attribute.Value, sequenceNumber(1))
+			}
+		}
+		if got, _ := a.sequenceService.Get().Next(ctx, "INV", "next", ""); got != sequenceNumber(2) {
+			t.Errorf("next number is %s, want %s", got, sequenceNumber(2))
+		}
+	})
+}
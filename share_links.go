@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+const (
+	defaultShareDays = 7
+	maxShareDays     = 90
+)
+
+var (
+	ErrShareLinkInvalid = errors.New("share link is invalid")
+	ErrShareLinkExpired = errors.New("share link has expired or was revoked")
+)
+
+type ShareLinks interface {
+	Create(ctx context.Context, link ShareLink, pdf []byte) (ShareLink, string, error)
+	Resolve(ctx context.Context, token string) (ShareLink, []byte, error)
+	Get(ctx context.Context, id string) (ShareLink, error)
+	List(ctx context.Context) ([]ShareLink, error)
+	Revoke(ctx context.Context, id string) (ShareLink, error)
+}
+
+// ShareLink gives people without an account access to the PDF of a single
+// document. Pinned links serve the PDF rendered when the link was created,
+// the others always serve the newest version.
+type ShareLink struct {
+	weaver.AutoMarshal
+	ID        string    `json:"id"`
+	Document  string    `json:"document"`
+	Pinned    bool      `json:"pinned"`
+	Version   int       `json:"version,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	RevokedAt time.Time `json:"revokedAt,omitempty"`
+}
+
+func (l ShareLink) Active() bool {
+	return l.RevokedAt.IsZero() && time.Now().Before(l.ExpiresAt)
+}
+
+type shareLinksConfig struct {
+	// MaxDays limits how long a link may be valid, 90 days by default.
+	MaxDays int `toml:"max_days"`
+}
+
+// shareClaims is the signed content of a share token.
+type shareClaims struct {
+	ID      string `json:"id"`
+	Expires int64  `json:"exp"`
+}
+
+// Implementation of the ShareLinks component.
+type shareLinks struct {
+	weaver.Implements[ShareLinks]
+	weaver.WithConfig[shareLinksConfig]
+	secret []byte
+	mu     sync.Mutex
+}
+
+func (s *shareLinks) dir() string {
+	return filepath.Join(workDirName, "shares")
+}
+
+// Init loads the signing secret from SUDOCU_SHARE_SECRET or from the work
+// folder, where one is created on first start. Links that were sent out must
+// stay valid across restarts.
+func (s *shareLinks) Init(context.Context) error {
+	if secret := os.Getenv("SUDOCU_SHARE_SECRET"); secret != "" {
+		s.secret = []byte(secret)
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir(), 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir(), "secret")
+	secret, err := ioutil.ReadFile(path)
+	if err == nil && len(secret) > 0 {
+		s.secret = secret
+		return nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	s.secret = make([]byte, 32)
+	if _, err := rand.Read(s.secret); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, s.secret, 0600)
+}
+
+// Create registers a link and returns it with its token. The pdf is stored
+// for pinned links.
+func (s *shareLinks) Create(ctx context.Context, link ShareLink, pdf []byte) (ShareLink, string, error) {
+	maxDays := s.Config().MaxDays
+	if maxDays <= 0 {
+		maxDays = maxShareDays
+	}
+	link.CreatedAt = time.Now()
+	if link.ExpiresAt.IsZero() {
+		link.ExpiresAt = link.CreatedAt.AddDate(0, 0, defaultShareDays)
+	}
+	if !link.ExpiresAt.After(link.CreatedAt) || link.ExpiresAt.After(link.CreatedAt.AddDate(0, 0, maxDays)) {
+		return ShareLink{}, "", fmt.Errorf("%w: links expire within %d days", errInvalidRequest, maxDays)
+	}
+
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return ShareLink{}, "", err
+	}
+	link.ID = hex.EncodeToString(id)
+	link.RevokedAt = time.Time{}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if link.Pinned {
+		if err := ioutil.WriteFile(filepath.Join(s.dir(), link.ID+".pdf"), pdf, 0600); err != nil {
+			return ShareLink{}, "", err
+		}
+	}
+
+	links, err := s.readLinks()
+	if err != nil {
+		return ShareLink{}, "", err
+	}
+	links = append(links, link)
+	if err := s.writeLinks(links); err != nil {
+		return ShareLink{}, "", err
+	}
+
+	s.Logger().Info("Created share link", "id", link.ID, "document", link.Document, "by", link.CreatedBy)
+	return link, s.token(link), nil
+}
+
+func (s *shareLinks) token(link ShareLink) string {
+	payload, _ := json.Marshal(shareClaims{ID: link.ID, Expires: link.ExpiresAt.Unix()})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+}
+
+func (s *shareLinks) sign(data string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Resolve checks a token and returns its link, with the stored PDF if the
+// link is pinned.
+func (s *shareLinks) Resolve(ctx context.Context, token string) (ShareLink, []byte, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ShareLink{}, nil, ErrShareLinkInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return ShareLink{}, nil, ErrShareLinkInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ShareLink{}, nil, ErrShareLinkInvalid
+	}
+	var claims shareClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ShareLink{}, nil, ErrShareLinkInvalid
+	}
+	if time.Now().Unix() >= claims.Expires {
+		return ShareLink{}, nil, ErrShareLinkExpired
+	}
+
+	link, err := s.Get(ctx, claims.ID)
+	if err != nil {
+		return ShareLink{}, nil, err
+	}
+	if !link.Active() {
+		return ShareLink{}, nil, ErrShareLinkExpired
+	}
+	if !link.Pinned {
+		return link, nil, nil
+	}
+
+	pdf, err := ioutil.ReadFile(filepath.Join(s.dir(), link.ID+".pdf"))
+	if err != nil {
+		return ShareLink{}, nil, err
+	}
+	return link, pdf, nil
+}
+
+func (s *shareLinks) Get(ctx context.Context, id string) (ShareLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	links, err := s.readLinks()
+	if err != nil {
+		return ShareLink{}, err
+	}
+	for _, link := range links {
+		if link.ID == id {
+			return link, nil
+		}
+	}
+	return ShareLink{}, ErrShareLinkInvalid
+}
+
+// List returns all links, newest first.
+func (s *shareLinks) List(ctx context.Context) ([]ShareLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	links, err := s.readLinks()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].CreatedAt.After(links[j].CreatedAt)
+	})
+	return links, nil
+}
+
+// Revoke disables a link for good. The pinned PDF is deleted, the link is
+// kept to show who shared what.
+func (s *shareLinks) Revoke(ctx context.Context, id string) (ShareLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	links, err := s.readLinks()
+	if err != nil {
+		return ShareLink{}, err
+	}
+	for i, link := range links {
+		if link.ID != id {
+			continue
+		}
+		if link.RevokedAt.IsZero() {
+			links[i].RevokedAt = time.Now()
+		}
+		if err := s.writeLinks(links); err != nil {
+			return ShareLink{}, err
+		}
+		if err := os.Remove(filepath.Join(s.dir(), id+".pdf")); err != nil && !os.IsNotExist(err) {
+			return ShareLink{}, err
+		}
+		s.Logger().Info("Revoked share link", "id", id, "document", link.Document)
+		return links[i], nil
+	}
+	return ShareLink{}, ErrShareLinkInvalid
+}
+
+func (s *shareLinks) readLinks() ([]ShareLink, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir(), "links.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var links []ShareLink
+	err = json.Unmarshal(data, &links)
+	return links, err
+}
+
+func (s *shareLinks) writeLinks(links []ShareLink) error {
+	data, err := json.MarshalIndent(links, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so a crash cannot truncate the list
+	path := filepath.Join(s.dir(), "links.json")
+	if err := ioutil.WriteFile(path+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
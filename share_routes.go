@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type shareLinkRequest struct {
+	// Days the link stays valid, 7 by default.
+	Days int `json:"days,omitempty"`
+	// Pinned serves the PDF of Version as rendered now, instead of always
+	// the newest version. Setting a Version pins the link as well.
+	Pinned  bool `json:"pinned,omitempty"`
+	Version int  `json:"version,omitempty"`
+}
+
+type shareLinkResponse struct {
+	Link ShareLink `json:"link"`
+	URL  string    `json:"url"`
+}
+
+// createShareLink shares the PDF of a document through a signed link. The
+// document is rendered right away, so broken links are never sent out.
+func (a *app) createShareLink(r *http.Request, fileName string, request shareLinkRequest) (shareLinkResponse, error) {
+	if request.Days < 0 || request.Version < 0 {
+		return shareLinkResponse{}, fmt.Errorf("%w: days and version must not be negative", errInvalidRequest)
+	}
+
+	link := ShareLink{
+		Document:  fileName,
+		Pinned:    request.Pinned || request.Version != 0,
+		Version:   request.Version,
+		CreatedBy: requestUser(r).Name,
+	}
+	if request.Days != 0 {
+		link.ExpiresAt = time.Now().AddDate(0, 0, request.Days)
+	}
+
+	pdf, _, err := a.renderPDF(r.Context(), fileName, request.Version)
+	if err != nil {
+		return shareLinkResponse{}, err
+	}
+	if !link.Pinned {
+		pdf = nil
+	}
+
+	link, token, err := a.shareLinks.Get().Create(r.Context(), link, pdf)
+	if err != nil {
+		return shareLinkResponse{}, err
+	}
+	return shareLinkResponse{Link: link, URL: shareLinkURL(r, token)}, nil
+}
+
+// sharedPDFCacheDir keeps the renders of the newest versions served by links
+// that are not pinned.
+var sharedPDFCacheDir = filepath.Join(workDirName, "share-cache")
+
+// sharedPDF returns the newest PDF of a shared document. Drafts are rendered
+// once per content and cached, so anonymous requests only run asciidoctor-pdf
+// after the document changed. Only the newest render of a document is kept.
+func (a *app) sharedPDF(ctx context.Context, fileName string) ([]byte, error) {
+	state, err := a.aDocRepository.Get().GetState(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if state.Locked() {
+		pdf, _, err := a.renderPDF(ctx, fileName, 0)
+		return pdf, err
+	}
+
+	content, err := a.aDocRepository.Get().ReadVersion(ctx, fileName, 0)
+	if err != nil {
+		return nil, err
+	}
+	prefix, path := sharedPDFPath(fileName, content)
+	if pdf, err := ioutil.ReadFile(path); err == nil {
+		return pdf, nil
+	}
+
+	// Requests arriving while the document is rendered wait for that render
+	a.sharedRenders.Lock()
+	defer a.sharedRenders.Unlock()
+	if pdf, err := ioutil.ReadFile(path); err == nil {
+		return pdf, nil
+	}
+
+	pdf, err := a.pdfGenerator.Get().GeneratePDF(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeSharedPDF(prefix, path, pdf); err != nil {
+		a.Logger().Warn("Failed to cache shared PDF", "document", fileName, "err", err)
+	}
+	return pdf, nil
+}
+
+// sharedPDFPath returns the prefix all cached renders of a document share and
+// the path of the render of its content.
+func sharedPDFPath(fileName string, content []byte) (string, string) {
+	document := sha256.Sum256([]byte(fileName))
+	markup := sha256.Sum256(content)
+	prefix := hex.EncodeToString(document[:8]) + "_"
+	return prefix, filepath.Join(sharedPDFCacheDir, prefix+hex.EncodeToString(markup[:])+".pdf")
+}
+
+// writeSharedPDF caches a render and removes the older ones of the document.
+func writeSharedPDF(prefix string, path string, pdf []byte) error {
+	if err := os.MkdirAll(sharedPDFCacheDir, 0755); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(sharedPDFCacheDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), prefix) {
+			os.Remove(filepath.Join(sharedPDFCacheDir, file.Name()))
+		}
+	}
+	if err := ioutil.WriteFile(path+".tmp", pdf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// shareLinkURL builds the absolute URL of a token from the host the link was
+// requested on.
+func shareLinkURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/s/" + token
+}
+
+// visibleShareLinks returns the links of the documents the user owns.
+func (a *app) visibleShareLinks(r *http.Request) ([]ShareLink, error) {
+	links, err := a.shareLinks.Get().List(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	visible := []ShareLink{}
+	for _, link := range links {
+		err := a.authorizeRequest(r, link.Document, RoleOwner)
+		if errors.Is(err, ErrForbidden) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		visible = append(visible, link)
+	}
+	return visible, nil
+}
+
+// revokeShareLink revokes a link if the user owns its document.
+func (a *app) revokeShareLink(r *http.Request, id string) (ShareLink, error) {
+	link, err := a.shareLinks.Get().Get(r.Context(), id)
+	if err != nil {
+		return ShareLink{}, err
+	}
+	if err := a.authorizeRequest(r, link.Document, RoleOwner); err != nil {
+		return ShareLink{}, err
+	}
+	return a.shareLinks.Get().Revoke(r.Context(), id)
+}
+
+func (a *app) registerShareLinks(router *mux.Router) {
+	router.HandleFunc("/share/{filename}", func(w http.ResponseWriter, r *http.Request) {
+		var request shareLinkRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+
+		response, err := a.createShareLink(r, mux.Vars(r)["filename"], request)
+		if errors.Is(err, errInvalidRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, ErrNoSuchVersion) || errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			a.Logger().Warn(err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			a.Logger().Warn("Error writing response:", err)
+		}
+	}).Methods(http.MethodPost)
+
+	// Public, the signed token is the only credential
+	router.HandleFunc("/s/{token}", func(w http.ResponseWriter, r *http.Request) {
+		link, pdf, err := a.shareLinks.Get().Resolve(r.Context(), mux.Vars(r)["token"])
+		if errors.Is(err, ErrShareLinkInvalid) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if errors.Is(err, ErrShareLinkExpired) {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			a.Logger().Warn(err.Error())
+			return
+		}
+
+		if pdf == nil {
+			pdf, err = a.sharedPDF(r.Context(), link.Document)
+			if err != nil {
+				http.Error(w, "The shared document is not available", http.StatusNotFound)
+				a.Logger().Warn(err.Error())
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", "inline; filename="+link.Document+".pdf")
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Header().Set("X-Robots-Tag", "noindex")
+		if _, err := w.Write(pdf); err != nil {
+			a.Logger().Warn("Error writing response:", err)
+		}
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/shares", func(w http.ResponseWriter, r *http.Request) {
+		links, err := a.visibleShareLinks(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			a.Logger().Warn(err.Error())
+			return
+		}
+
+		tmpl, err := template.ParseFiles("shares.html")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			a.Logger().Warn(err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		if err := tmpl.Execute(w, map[string]interface{}{"Links": links}); err != nil {
+			a.Logger().Warn("Error writing response:", err)
+		}
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/shares/{id}/revoke", func(w http.ResponseWriter, r *http.Request) {
+		link, err := a.revokeShareLink(r, mux.Vars(r)["id"])
+		if errors.Is(err, ErrShareLinkInvalid) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			a.writeAuthorizationError(w, r, err)
+			return
+		}
+
+		// The admin view posts a form and goes back to the list
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			http.Redirect(w, r, "/shares", http.StatusSeeOther)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(link); err != nil {
+			a.Logger().Warn("Error writing response:", err)
+		}
+	}).Methods(http.MethodPost)
+}
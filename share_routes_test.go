@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/gorilla/mux"
+)
+
+// TestSharedPDFIsCachedPerContent checks that links that are not pinned serve
+// the cached render of the newest content and render again after a change.
+func TestSharedPDFIsCachedPerContent(t *testing.T) {
+	markup := "= Invoice\n\nText\n"
+	inWorkspace(t, map[string]string{"invoice": markup})
+	runner := weavertest.Local
+	runner.Config = `
+["sudocu/Authenticator"]
+disabled = true
+`
+	runner.Test(t, func(t *testing.T, a *app) {
+		ctx := context.Background()
+		router := mux.NewRouter()
+		router.Use(a.authenticate, a.canonicalizeDocument, a.authorize)
+		a.registerShareLinks(router)
+
+		_, token, err := a.shareLinks.Get().Create(ctx, ShareLink{Document: "invoice"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		get := func() *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/s/"+token, nil))
+			return recorder
+		}
+
+		cached := []byte("%PDF cached")
+		_, path := sharedPDFPath("invoice", []byte(markup))
+		if err := os.MkdirAll(sharedPDFCacheDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, cached, 0644); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if recorder := get(); recorder.Code != http.StatusOK || recorder.Body.String() != string(cached) {
+				t.Fatalf("got %d %q, want the cached render", recorder.Code, recorder.Body.String())
+			}
+		}
+
+		if err := a.aDocRepository.Get().SaveVariantForFile(ctx, "invoice", []byte("= Invoice\n\nChanged\n"), "", ""); err != nil {
+			t.Fatal(err)
+		}
+		if recorder := get(); recorder.Body.String() == string(cached) {
+			t.Errorf("a changed document was served from the cache")
+		}
+	})
+}
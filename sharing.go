@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// routeRoles lists the routes that need another role than viewer for reading
+// and editor for changing a document. Reading aloud and transcribing cost
+// money, so they need editors like changes do.
+var routeRoles = map[string]string{
+	"POST /command/{filename}":                                RoleViewer,
+	"GET /speech/stream":                                      RoleEditor,
+	"GET /tts/{filename}":                                     RoleEditor,
+	"GET /tts/{filename}/changes":                             RoleEditor,
+	"POST /adoc/{filename}/finalize":                          RoleOwner,
+	"POST /adoc/{filename}/archive":                           RoleOwner,
+	"GET /adoc/{filename}/access":                             RoleOwner,
+	"PUT /adoc/{filename}/access/{user}":                      RoleOwner,
+	"DELETE /adoc/{filename}/access/{user}":                   RoleOwner,
+	"POST /share/{filename}":                                  RoleOwner,
+	"POST " + apiPrefix + "/documents/{name}/finalize":        RoleOwner,
+	"POST " + apiPrefix + "/documents/{name}/archive":         RoleOwner,
+	"GET " + apiPrefix + "/documents/{name}/access":           RoleOwner,
+	"PUT " + apiPrefix + "/documents/{name}/access/{user}":    RoleOwner,
+	"DELETE " + apiPrefix + "/documents/{name}/access/{user}": RoleOwner,
+	"POST " + apiPrefix + "/documents/{name}/share-links":     RoleOwner,
+}
+
+// authorize is the middleware that checks the role of the user on the
+// document a request is about. Routes without a document check it in the
+// handler, or filter what they return.
+func (a *app) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		template := ""
+		if route := mux.CurrentRoute(r); route != nil {
+			template, _ = route.GetPathTemplate()
+		}
+
+		role, explicit := routeRoles[r.Method+" "+template]
+		if !explicit {
+			role = RoleEditor
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				role = RoleViewer
+			}
+		}
+
+		fileName := requestDocument(r)
+		if fileName == "" && !explicit {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := a.authorizeRequest(r, fileName, role); err != nil {
+			a.writeAuthorizationError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// canonicalizeDocument is the middleware that replaces the document name in
+// the path and query of a request by its canonical form before any handler
+// sees it. "invoice.adoc" and "invoice" then share roles, budgets, numbers and
+// share links. Invalid names are rejected.
+func (a *app) canonicalizeDocument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		for _, key := range []string{"filename", "name"} {
+			if value, ok := vars[key]; ok {
+				name, err := parseDocumentName(value)
+				if err != nil {
+					a.writeAuthorizationError(w, r, err)
+					return
+				}
+				vars[key] = string(name)
+			}
+		}
+
+		query := r.URL.Query()
+		for _, key := range []string{"filename", "document"} {
+			if value := query.Get(key); value != "" {
+				name, err := parseDocumentName(value)
+				if err != nil {
+					a.writeAuthorizationError(w, r, err)
+					return
+				}
+				query.Set(key, string(name))
+				r.URL.RawQuery = query.Encode()
+			}
+		}
+
+		next.ServeHTTP(w, mux.SetURLVars(r, vars))
+	})
+}
+
+// canonicalDocument canonicalizes a document name sent in a form body, which
+// the middleware leaves alone. An empty name stays empty.
+func canonicalDocument(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	parsed, err := parseDocumentName(name)
+	return string(parsed), err
+}
+
+// requestDocument finds the document a request is about in the path or in
+// the query. Form bodies are left to the handlers, which limit their size.
+func requestDocument(r *http.Request) string {
+	vars := mux.Vars(r)
+	for _, name := range []string{vars["filename"], vars["name"], r.URL.Query().Get("filename"), r.URL.Query().Get("document")} {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// authorizeRequest checks the role of the sender of a request. An empty
+// fileName checks the role on all documents.
+func (a *app) authorizeRequest(r *http.Request, fileName string, role string) error {
+	return a.aDocRepository.Get().Authorize(r.Context(), fileName, requestUser(r).Name, role)
+}
+
+func (a *app) writeAuthorizationError(w http.ResponseWriter, r *http.Request, err error) {
+	if strings.HasPrefix(r.URL.Path, apiPrefix+"/") {
+		status, code := apiStatus(err)
+		a.writeAPIError(w, status, code, err.Error())
+		return
+	}
+
+	if errors.Is(err, ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	} else if errors.Is(err, ErrInvalidDocumentName) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+	a.Logger().Warn(err.Error())
+}
+
+type shareRequest struct {
+	Role string `json:"role"`
+}
+
+func (a *app) registerSharing(router *mux.Router) {
+	router.HandleFunc("/adoc/{filename}/access", func(w http.ResponseWriter, r *http.Request) {
+		access, err := a.aDocRepository.Get().GetAccess(r.Context(), mux.Vars(r)["filename"])
+		a.writeAccess(w, access, err)
+	}).Methods(http.MethodGet)
+
+	router.HandleFunc("/adoc/{filename}/access/{user}", func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		role := ""
+		if r.Method == http.MethodPut {
+			var request shareRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			if request.Role == "" {
+				http.Error(w, ErrInvalidRole.Error(), http.StatusBadRequest)
+				return
+			}
+			role = request.Role
+		}
+
+		access, err := a.aDocRepository.Get().Share(r.Context(), vars["filename"], vars["user"], role)
+		a.writeAccess(w, access, err)
+	}).Methods(http.MethodPut, http.MethodDelete)
+}
+
+func (a *app) writeAccess(w http.ResponseWriter, access AccessList, err error) {
+	if errors.Is(err, ErrInvalidRole) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		a.Logger().Warn(err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(access); err != nil {
+		a.Logger().Warn("Error writing response:", err)
+	}
+}
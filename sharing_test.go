@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/gorilla/mux"
+)
+
+func apiKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// TestPaidRoutesNeedEditors checks that viewers cannot spend money on
+// speech, while reading stays open to them.
+func TestPaidRoutesNeedEditors(t *testing.T) {
+	inWorkspace(t, map[string]string{"invoice": "= Invoice\n\nText\n"})
+	runner := weavertest.Local
+	runner.Config = fmt.Sprintf(`
+["sudocu/Authenticator"]
+api_keys = [{user = "vera", sha256 = %q}, {user = "ed", sha256 = %q}]
+
+["sudocu/ADocRepository"]
+folder_roles = {"vera" = "viewer", "ed" = "editor"}
+`, apiKeyHash("viewer-key"), apiKeyHash("editor-key"))
+
+	runner.Test(t, func(t *testing.T, a *app) {
+		router := mux.NewRouter()
+		router.Use(a.authenticate, a.canonicalizeDocument, a.authorize)
+		ok := func(w http.ResponseWriter, r *http.Request) {}
+		for _, path := range []string{"/pdf/{filename}", "/tts/{filename}", "/tts/{filename}/changes", "/speech/stream"} {
+			router.HandleFunc(path, ok).Methods(http.MethodGet)
+		}
+
+		tests := []struct {
+			key    string
+			target string
+			status int
+		}{
+			{"viewer-key", "/pdf/invoice", http.StatusOK},
+			{"viewer-key", "/tts/invoice", http.StatusForbidden},
+			{"viewer-key", "/tts/invoice.adoc/changes", http.StatusForbidden},
+			{"viewer-key", "/speech/stream?filename=invoice", http.StatusForbidden},
+			{"editor-key", "/tts/invoice", http.StatusOK},
+			{"editor-key", "/tts/invoice/changes", http.StatusOK},
+			{"editor-key", "/speech/stream?filename=invoice", http.StatusOK},
+		}
+		for _, test := range tests {
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			req.Header.Set("X-API-Key", test.key)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			if recorder.Code != test.status {
+				t.Errorf("%s with %s: status %d, want %d", test.target, test.key, recorder.Code, test.status)
+			}
+		}
+	})
+}
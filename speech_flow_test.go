@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/gorilla/websocket"
+)
+
+// TestSpeechFlowWithFakeBackend runs voice prompts through the server, from
+// the upload and the stream to the transcript, with the fake speech backend.
+func TestSpeechFlowWithFakeBackend(t *testing.T) {
+	inWorkspace(t, map[string]string{"invoice": "= Invoice\n\nText\n"})
+	runner := weavertest.Local
+	runner.Config = `
+["sudocu/Authenticator"]
+disabled = true
+
+["sudocu/SpeechRepository"]
+backend = "fake"
+fake_text = "Add a line item for travel"
+
+["sudocu/VoiceArchive"]
+enabled = true
+`
+	runner.Test(t, func(t *testing.T, a *app) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go serve(ctx, a)
+		addr := a.listener.Addr().String()
+		recording := append([]byte{0x1A, 0x45, 0xDF, 0xA3}, webmCluster(20<<10, 1)...)
+
+		// A recorded clip
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("language", "en")
+		form.WriteField("filename", "invoice.adoc")
+		file, _ := form.CreateFormFile("voicePrompt", "prompt.webm")
+		file.Write(recording)
+		form.Close()
+		resp, err := http.Post("http://"+addr+"/speech-to-text", form.FormDataContentType(), &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(text) != "Add a line item for travel" {
+			t.Fatalf("speech-to-text answered %d %q", resp.StatusCode, text)
+		}
+		if resp.Header.Get("X-Voice-Prompt-ID") == "" {
+			t.Errorf("speech-to-text did not name the archived clip")
+		}
+
+		// A streamed recording gets partial transcripts and a final one
+		conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/speech/stream?language=en&filename=invoice", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+		conn.WriteMessage(websocket.BinaryMessage, recording)
+		time.Sleep(defaultPartialInterval)
+		conn.WriteMessage(websocket.BinaryMessage, webmCluster(20<<10, 2))
+		var partial speechStreamMessage
+		if err := conn.ReadJSON(&partial); err != nil {
+			t.Fatal(err)
+		}
+		if partial.Type != "partial" || partial.Text != "Add a line item for travel" {
+			t.Errorf("got %+v, want a partial transcript", partial)
+		}
+
+		conn.WriteMessage(websocket.TextMessage, []byte("end"))
+		var final speechStreamMessage
+		if err := conn.ReadJSON(&final); err != nil {
+			t.Fatal(err)
+		}
+		if final.Type != "final" || final.Text != "Add a line item for travel" {
+			t.Errorf("got %+v, want the final transcript", final)
+		}
+		if !voicePromptIDPattern.MatchString(final.VoicePromptID) {
+			t.Fatalf("final message names the archived clip %q", final.VoicePromptID)
+		}
+
+		// The version saved from the dictated prompt links to the clip
+		documents := a.aDocRepository.Get()
+		if err := documents.SaveVariantForFile(ctx, "invoice", []byte("= Invoice\n\nTravel\n"), "", final.VoicePromptID); err != nil {
+			t.Fatal(err)
+		}
+		versions, err := documents.ListVersions(ctx, "invoice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(versions) == 0 || versions[0].VoicePromptID != final.VoicePromptID {
+			t.Errorf("versions %+v do not link to the clip %s", versions, final.VoicePromptID)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxVocabularyLength keeps the vocabulary prompt well below the 224 tokens
+// Whisper looks at.
+const maxVocabularyLength = 600
+
+var (
+	languagePattern = regexp.MustCompile(`^[a-z]{2}$`)
+	headingPattern  = regexp.MustCompile(`^=+\s+(.*?)\s*=*\s*$`)
+)
+
+// normalizeLanguage turns codes like "de-DE" or "DE" into the ISO-639-1 code
+// Whisper expects. It returns false for anything else.
+func normalizeLanguage(language string) (string, bool) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "-_"); i != -1 {
+		language = language[:i]
+	}
+	return language, languagePattern.MatchString(language)
+}
+
+// documentLanguage returns the `:lang:` attribute of a document.
+func documentLanguage(markup []byte) string {
+	for _, attribute := range parseHeaderAttributes(markup) {
+		if attribute.Name == "lang" {
+			if language, ok := normalizeLanguage(attribute.Value); ok {
+				return language
+			}
+		}
+	}
+	return ""
+}
+
+// transcriptionVocabulary lists the headings and attribute values of a
+// document, so that names and terms it contains are spelled the same way in
+// the transcript.
+func transcriptionVocabulary(markup []byte) string {
+	var terms []string
+	seen := make(map[string]bool)
+	add := func(term string) {
+		term = strings.TrimSpace(term)
+		if term == "" || seen[term] {
+			return
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+
+	for _, line := range strings.Split(string(markup), "\n") {
+		if match := headingPattern.FindStringSubmatch(strings.TrimRight(line, "\r")); match != nil {
+			add(match[1])
+		}
+	}
+	for _, attribute := range parseHeaderAttributes(markup) {
+		if attribute.Name != "lang" {
+			add(attribute.Value)
+		}
+	}
+
+	var vocabulary strings.Builder
+	for _, term := range terms {
+		if vocabulary.Len()+len(term)+2 > maxVocabularyLength {
+			break
+		}
+		if vocabulary.Len() > 0 {
+			vocabulary.WriteString(", ")
+		}
+		vocabulary.WriteString(term)
+	}
+	return vocabulary.String()
+}
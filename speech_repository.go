@@ -1,98 +1,138 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"mime/multipart"
-	"net/http"
-	"os"
 
 	"github.com/ServiceWeaver/weaver"
 )
 
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+	// defaultMaxAudioBytes is the upload limit of the OpenAI API.
+	defaultMaxAudioBytes = 25 << 20
+)
+
 type SpeechRepository interface {
-	SpeechToText(ctx context.Context, audio []byte) (string, error)
+	SpeechToText(ctx context.Context, audio []byte, options TranscriptionOptions) (string, error)
+}
+
+// TranscriptionOptions are hints that help the backend to recognize speech.
+type TranscriptionOptions struct {
+	weaver.AutoMarshal
+	// Language is an ISO-639-1 code like "de". Empty lets the backend guess.
+	Language string
+	// Prompt lists words the speaker is likely to use.
+	Prompt string
+}
+
+type speechConfig struct {
+	// Backend is one of "openai" (default), "whispercpp" or "fake".
+	Backend string `toml:"backend"`
+	// BaseURL of an OpenAI compatible API or of a whisper.cpp server.
+	BaseURL string `toml:"base_url"`
+	// Model is the transcription model of an OpenAI compatible API.
+	Model string `toml:"model"`
+	// Binary and ModelPath run whisper.cpp locally when no BaseURL is set.
+	Binary    string `toml:"binary"`
+	ModelPath string `toml:"model_path"`
+	// FakeText is returned by the fake backend for every clip.
+	FakeText string `toml:"fake_text"`
+	// TranscodeTo is "wav", "ogg", "webm" or "mp3" to convert clips with
+	// ffmpeg before they are sent to the backend. whisper.cpp always gets WAV.
+	TranscodeTo string `toml:"transcode_to"`
+	// MaxAudioBytes limits the size of a clip, 25MB by default.
+	MaxAudioBytes int `toml:"max_audio_bytes"`
+	// Outbound configures timeouts, retries and the circuit breaker of the
+	// openai and whispercpp server backends.
+	Outbound outboundConfig `toml:"outbound"`
 }
 
-// Implementation of the PDFGenerator component.
+// Implementation of the SpeechRepository component.
 type speechRepository struct {
 	weaver.Implements[SpeechRepository]
+	weaver.WithConfig[speechConfig]
+	transcriber transcriber
+	transcodeTo *audioFormat
 }
 
-func (s *speechRepository) SpeechToText(ctx context.Context, audio []byte) (string, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("OPENAI_API_KEY environment variable is not set")
-	}
+func (s *speechRepository) Init(context.Context) error {
+	config := s.Config()
+	client := newOutboundClient(config.Outbound, s.Logger())
 
-	// Create a multipart form
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", "openai.mp3")
-	if err != nil {
-		return "", fmt.Errorf("failed to create form file: %v", err)
+	switch config.Backend {
+	case "", "openai":
+		baseURL := config.BaseURL
+		if baseURL == "" {
+			baseURL = defaultOpenAIBaseURL
+		}
+		model := config.Model
+		if model == "" {
+			model = "whisper-1"
+		}
+		s.transcriber = &openAITranscriber{baseURL: baseURL, model: model, client: client, logger: s.Logger()}
+	case "whispercpp":
+		if config.BaseURL != "" {
+			s.transcriber = &whisperCppServerTranscriber{baseURL: config.BaseURL, client: client, logger: s.Logger()}
+		} else if config.ModelPath != "" {
+			binary := config.Binary
+			if binary == "" {
+				binary = "whisper-cli"
+			}
+			s.transcriber = &whisperCppBinaryTranscriber{binary: binary, modelPath: config.ModelPath}
+		} else {
+			return fmt.Errorf("whispercpp backend needs either base_url or model_path")
+		}
+		if config.TranscodeTo == "" {
+			s.transcodeTo = &audioFormatWAV
+		}
+	case "fake":
+		s.transcriber = &fakeTranscriber{text: config.FakeText}
+	default:
+		return fmt.Errorf("unknown speech backend %q", config.Backend)
 	}
-	part.Write(audio)
-
-	// Add other form fields
-	_ = writer.WriteField("model", "whisper-1")
 
-	err = writer.Close()
-	if err != nil {
-		return "", fmt.Errorf("failed to close multipart writer: %v", err)
+	if config.TranscodeTo != "" {
+		format, ok := audioFormatByName(config.TranscodeTo)
+		if !ok {
+			return fmt.Errorf("unknown transcode_to format %q", config.TranscodeTo)
+		}
+		s.transcodeTo = &format
 	}
 
-	s.Logger().Info("Sending request to Whisper API")
+	return nil
+}
 
-	// Create an HTTP request with the Whisper API endpoint
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/audio/transcriptions", body)
-	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request: %v", err)
+func (s *speechRepository) SpeechToText(ctx context.Context, audio []byte, options TranscriptionOptions) (string, error) {
+	maxAudioBytes := s.Config().MaxAudioBytes
+	if maxAudioBytes == 0 {
+		maxAudioBytes = defaultMaxAudioBytes
 	}
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// Send the request and get the response
-	client := http.DefaultClient
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send HTTP request: %v", err)
+	if len(audio) > maxAudioBytes {
+		return "", fmt.Errorf("%w: %d bytes, limit is %d", ErrAudioTooLarge, len(audio), maxAudioBytes)
 	}
-	defer resp.Body.Close()
 
-	// Read the response body
-	respBody, err := ioutil.ReadAll(resp.Body)
+	format, err := detectAudioFormat(audio)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
+		return "", err
 	}
 
-	s.Logger().Info("Received response from Whisper API: " + string(respBody))
-
-	// Check for errors in the response
-	if resp.StatusCode != http.StatusOK {
-		var errorResponse struct {
-			Error string `json:"error"`
-		}
-		err := json.Unmarshal(respBody, &errorResponse)
+	if s.transcodeTo != nil && (*s.transcodeTo != format || format == audioFormatWAV) {
+		// WAV is transcoded even to WAV to get the 16 kHz mono whisper.cpp needs
+		audio, err = transcodeAudio(ctx, audio, *s.transcodeTo)
 		if err != nil {
-			return "", fmt.Errorf("failed to unmarshal error response: %v", err)
+			return "", err
 		}
-		return "", fmt.Errorf("API request failed: %s", errorResponse.Error)
+		format = *s.transcodeTo
 	}
 
-	// Parse the response to get the transcribed text
-	var response struct {
-		Text string `json:"text"`
-	}
-	err = json.Unmarshal(respBody, &response)
+	text, err := s.transcriber.transcribe(ctx, audio, format, options)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse response: %v", err)
+		return "", err
 	}
 
-	s.Logger().Info("Received text from Whisper API: " + response.Text)
+	s.Logger().Info("Received text from speech backend: " + text)
 
-	return response.Text, nil
+	return text, nil
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// defaultPartialInterval is the minimum time between two partial
+	// transcripts of a stream.
+	defaultPartialInterval = 2 * time.Second
+
+	// minPartialBytes avoids transcribing streams that contain little more
+	// than a container header.
+	minPartialBytes = 16 << 10
+
+	// partialOverlapBytes of audio before the new part are transcribed again,
+	// so words cut at the start of the part are not lost.
+	partialOverlapBytes = 16 << 10
+)
+
+// webmClusterID starts every cluster of audio frames in a WebM stream.
+var webmClusterID = []byte{0x1F, 0x43, 0xB6, 0x75}
+
+// speechStreamMessage is sent to the client of a `/speech/stream` socket.
+// Type is "partial", "final" or "error".
+type speechStreamMessage struct {
+	Type  string `json:"type"`
+	Text  string `json:"text,omitempty"`
+	Error string `json:"error,omitempty"`
+	// VoicePromptID names the archived clip of a final message
+	VoicePromptID string `json:"voicePromptId,omitempty"`
+}
+
+// speechStream collects the chunks of one continuous recording and
+// transcribes what has been said so far at a fixed interval. Partial
+// transcripts only send the audio that arrived since the last one, with a
+// small overlap, so their cost grows with the length of the recording and
+// not with its square. Chunks of containers like WebM only carry a header in
+// the first chunk, so every part is sent behind that header and cut where a
+// cluster or page starts. The final transcript uses the complete recording.
+type speechStream struct {
+	speech   SpeechRepository
+	options  TranscriptionOptions
+	interval time.Duration
+
+	audio       []byte
+	lastPartial time.Time
+	partialLen  int
+	partialText string
+}
+
+func newSpeechStream(speech SpeechRepository, options TranscriptionOptions) *speechStream {
+	return &speechStream{
+		speech:      speech,
+		options:     options,
+		interval:    defaultPartialInterval,
+		lastPartial: time.Now(),
+	}
+}
+
+// write adds a chunk to the recording. It returns a partial transcript when
+// the interval has passed and new audio arrived since the last one.
+func (s *speechStream) write(ctx context.Context, chunk []byte) (string, bool, error) {
+	s.audio = append(s.audio, chunk...)
+
+	if len(s.audio) < minPartialBytes || len(s.audio) == s.partialLen || time.Since(s.lastPartial) < s.interval {
+		return "", false, nil
+	}
+
+	part, ok := s.newAudio()
+	if !ok {
+		return "", false, nil
+	}
+	text, err := s.speech.SpeechToText(ctx, part, s.options)
+	if err != nil {
+		return "", false, err
+	}
+
+	s.lastPartial = time.Now()
+	s.partialLen = len(s.audio)
+	s.partialText = joinTranscripts(s.partialText, text)
+	return s.partialText, true, nil
+}
+
+// newAudio returns the container header followed by the audio since the
+// last partial transcript and some overlap. It fails when the container is
+// unknown or has no place to cut yet.
+func (s *speechStream) newAudio() ([]byte, bool) {
+	format, err := detectAudioFormat(s.audio)
+	if err != nil {
+		return nil, false
+	}
+	header, ok := audioHeaderLength(s.audio, format)
+	if !ok {
+		return nil, false
+	}
+
+	from := s.partialLen - partialOverlapBytes
+	if from <= header {
+		return s.audio, true
+	}
+	start, ok := audioCutPoint(s.audio, format, header, from)
+	if !ok {
+		return nil, false
+	}
+
+	part := make([]byte, 0, header+len(s.audio)-start)
+	part = append(part, s.audio[:header]...)
+	return append(part, s.audio[start:]...), true
+}
+
+// audioHeaderLength returns the length of the container header that has to
+// precede any part of a recording.
+func audioHeaderLength(audio []byte, format audioFormat) (int, bool) {
+	switch format {
+	case audioFormatWebM:
+		// Everything up to the first cluster describes the tracks
+		i := bytes.Index(audio, webmClusterID)
+		return i, i > 0
+	case audioFormatOGG:
+		// The first two pages hold the identification and comment headers
+		first := bytes.Index(audio[4:], []byte("OggS"))
+		if first == -1 {
+			return 0, false
+		}
+		second := bytes.Index(audio[first+8:], []byte("OggS"))
+		return first + 8 + second, second != -1
+	case audioFormatWAV:
+		for offset := 12; offset+8 <= len(audio); {
+			if string(audio[offset:offset+4]) == "data" {
+				return offset + 8, true
+			}
+			offset += 8 + int(binary.LittleEndian.Uint32(audio[offset+4:offset+8]))
+		}
+		return 0, false
+	default:
+		// MP3 frames stand on their own
+		return 0, true
+	}
+}
+
+// audioCutPoint finds an offset close to from where a part of the recording
+// can start.
+func audioCutPoint(audio []byte, format audioFormat, header int, from int) (int, bool) {
+	var marker []byte
+	switch format {
+	case audioFormatWebM:
+		marker = webmClusterID
+	case audioFormatOGG:
+		marker = []byte("OggS")
+	case audioFormatWAV:
+		// Keep 16-bit samples of stereo recordings whole
+		return from - (from-header)%4, true
+	default:
+		return from, true
+	}
+
+	i := bytes.Index(audio[from:], marker)
+	return from + i, i != -1
+}
+
+// joinTranscripts appends the transcript of a part to the text so far. The
+// words the overlap of both share are only kept once.
+func joinTranscripts(text string, part string) string {
+	words, partWords := strings.Fields(text), strings.Fields(part)
+	normalize := func(word string) string {
+		return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		}))
+	}
+
+	// Only the few words of the overlap can be shared
+	for n := minInt(minInt(len(words), len(partWords)), 20); n > 0; n-- {
+		shared := true
+		for i := 0; i < n && shared; i++ {
+			shared = normalize(words[len(words)-n+i]) == normalize(partWords[i])
+		}
+		if shared {
+			return strings.Join(append(words, partWords[n:]...), " ")
+		}
+	}
+	return strings.Join(append(words, partWords...), " ")
+}
+
+// finish transcribes the complete recording.
+func (s *speechStream) finish(ctx context.Context) (string, error) {
+	return s.speech.SpeechToText(ctx, s.audio, s.options)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+// recordingSpeech answers every clip with the next text and keeps the clips.
+type recordingSpeech struct {
+	texts []string
+	clips [][]byte
+}
+
+func (r *recordingSpeech) SpeechToText(ctx context.Context, audio []byte, options TranscriptionOptions) (string, error) {
+	r.clips = append(r.clips, append([]byte(nil), audio...))
+	text := r.texts[0]
+	if len(r.texts) > 1 {
+		r.texts = r.texts[1:]
+	}
+	return text, nil
+}
+
+// webmCluster returns a cluster of n bytes that starts with the cluster ID.
+func webmCluster(n int, fill byte) []byte {
+	return append(append([]byte(nil), webmClusterID...), bytes.Repeat([]byte{fill}, n-len(webmClusterID))...)
+}
+
+func TestSpeechStreamTranscribesNewAudio(t *testing.T) {
+	header := append([]byte{0x1A, 0x45, 0xDF, 0xA3}, bytes.Repeat([]byte{0}, 60)...)
+	speech := &recordingSpeech{texts: []string{"add a line item", "line item for travel", "add a line item for travel"}}
+	stream := newSpeechStream(speech, TranscriptionOptions{})
+	stream.interval = 0
+
+	write := func(chunk []byte) (string, bool) {
+		t.Helper()
+		text, ok, err := stream.write(context.Background(), chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return text, ok
+	}
+
+	if text, ok := write(append(header, webmCluster(40<<10, 1)...)); !ok || text != "add a line item" {
+		t.Fatalf("first partial = %q, %v", text, ok)
+	}
+	for i := 0; i < 3; i++ {
+		write(nil)
+	}
+	if len(speech.clips) != 1 {
+		t.Fatalf("got %d transcriptions without new audio, want 1", len(speech.clips))
+	}
+
+	// The second part starts at the cluster before the overlap
+	text, ok := write(webmCluster(40<<10, 2))
+	if !ok || text != "add a line item for travel" {
+		t.Fatalf("second partial = %q, %v", text, ok)
+	}
+	part := speech.clips[1]
+	if !bytes.HasPrefix(part, header) || !bytes.Equal(part[len(header):len(header)+4], webmClusterID) {
+		t.Errorf("second part does not start with the header and a cluster")
+	}
+	if len(part) >= len(stream.audio) {
+		t.Errorf("second part has %d bytes, the whole recording %d", len(part), len(stream.audio))
+	}
+
+	// Only the final transcript uses the whole recording
+	final, err := stream.finish(context.Background())
+	if err != nil || final != "add a line item for travel" {
+		t.Fatalf("final = %q, %v", final, err)
+	}
+	if !bytes.Equal(speech.clips[2], stream.audio) {
+		t.Errorf("final transcript did not use the whole recording")
+	}
+}
+
+func TestSpeechStreamWaitsForInterval(t *testing.T) {
+	speech := &recordingSpeech{texts: []string{"hello"}}
+	stream := newSpeechStream(speech, TranscriptionOptions{})
+	stream.lastPartial = time.Now()
+
+	audio := append([]byte{0x1A, 0x45, 0xDF, 0xA3}, webmCluster(40<<10, 1)...)
+	if _, ok, _ := stream.write(context.Background(), audio); ok || len(speech.clips) != 0 {
+		t.Errorf("transcribed before the interval passed")
+	}
+}
+
+func TestJoinTranscripts(t *testing.T) {
+	tests := []struct{ text, part, want string }{
+		{"", "hello", "hello"},
+		{"add a line item", "line item for travel", "add a line item for travel"},
+		{"Add a line item.", "Item, for travel.", "Add a line item. for travel."},
+		{"hello", "world", "hello world"},
+	}
+	for _, test := range tests {
+		if got := joinTranscripts(test.text, test.part); got != test.want {
+			t.Errorf("joinTranscripts(%q, %q) = %q, want %q", test.text, test.part, got, test.want)
+		}
+	}
+}
+
+func TestAudioHeaderLength(t *testing.T) {
+	ogg := []byte("OggS..identification..OggS..comments..OggS..audio..")
+	if got, ok := audioHeaderLength(ogg, audioFormatOGG); !ok || got != bytes.LastIndex(ogg, []byte("OggS")) {
+		t.Errorf("OGG header ends at %d, %v", got, ok)
+	}
+	if _, ok := audioHeaderLength(ogg[:30], audioFormatOGG); ok {
+		t.Errorf("OGG header found before the audio pages")
+	}
+
+	wav := append([]byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00"), make([]byte, 16)...)
+	wav = append(wav, []byte("data\xff\xff\xff\xff")...)
+	if got, ok := audioHeaderLength(append(wav, 1, 2, 3, 4), audioFormatWAV); !ok || got != len(wav) {
+		t.Errorf("WAV header ends at %d, %v, want %d", got, ok, len(wav))
+	}
+}
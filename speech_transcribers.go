@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// transcriber is a speech-to-text backend of the SpeechRepository.
+type transcriber interface {
+	transcribe(ctx context.Context, audio []byte, format audioFormat, options TranscriptionOptions) (string, error)
+}
+
+// openAITranscriber talks to the OpenAI transcription API or any server that
+// implements the same `/audio/transcriptions` endpoint.
+type openAITranscriber struct {
+	baseURL string
+	model   string
+	client  *outboundClient
+	logger  *slog.Logger
+}
+
+func (t *openAITranscriber) transcribe(ctx context.Context, audio []byte, format audioFormat, options TranscriptionOptions) (string, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" && t.baseURL == defaultOpenAIBaseURL {
+		return "", fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+	}
+
+	// Create a multipart form
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := createAudioFormFile(writer, format)
+	if err != nil {
+		return "", fmt.Errorf("failed to create form file: %v", err)
+	}
+	part.Write(audio)
+
+	// Add other form fields
+	_ = writer.WriteField("model", t.model)
+	if options.Language != "" {
+		_ = writer.WriteField("language", options.Language)
+	}
+	if options.Prompt != "" {
+		_ = writer.WriteField("prompt", options.Prompt)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to close multipart writer: %v", err)
+	}
+
+	t.logger.Info("Sending request to transcription API", "url", t.baseURL)
+
+	url := strings.TrimSuffix(t.baseURL, "/") + "/audio/transcriptions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	respBody, err := doTranscriptionRequest(t.client, req)
+	if err != nil {
+		return "", err
+	}
+
+	t.logger.Info("Received response from transcription API: " + string(respBody))
+
+	return parseTranscriptionResponse(respBody)
+}
+
+// whisperCppServerTranscriber uses the `/inference` endpoint of the
+// whisper.cpp example server.
+type whisperCppServerTranscriber struct {
+	baseURL string
+	client  *outboundClient
+	logger  *slog.Logger
+}
+
+func (t *whisperCppServerTranscriber) transcribe(ctx context.Context, audio []byte, format audioFormat, options TranscriptionOptions) (string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := createAudioFormFile(writer, format)
+	if err != nil {
+		return "", fmt.Errorf("failed to create form file: %v", err)
+	}
+	part.Write(audio)
+
+	_ = writer.WriteField("response_format", "json")
+	if options.Language != "" {
+		_ = writer.WriteField("language", options.Language)
+	}
+	if options.Prompt != "" {
+		_ = writer.WriteField("prompt", options.Prompt)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to close multipart writer: %v", err)
+	}
+
+	t.logger.Info("Sending request to whisper.cpp server", "url", t.baseURL)
+
+	url := strings.TrimSuffix(t.baseURL, "/") + "/inference"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	respBody, err := doTranscriptionRequest(t.client, req)
+	if err != nil {
+		return "", err
+	}
+
+	return parseTranscriptionResponse(respBody)
+}
+
+// whisperCppBinaryTranscriber runs the whisper.cpp command line tool on a
+// temporary copy of the clip.
+type whisperCppBinaryTranscriber struct {
+	binary    string
+	modelPath string
+}
+
+func (t *whisperCppBinaryTranscriber) transcribe(ctx context.Context, audio []byte, format audioFormat, options TranscriptionOptions) (string, error) {
+	dir, err := ioutil.TempDir("", "sudocu-speech")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	audioPath := filepath.Join(dir, "audio."+format.extension)
+	if err := ioutil.WriteFile(audioPath, audio, 0600); err != nil {
+		return "", err
+	}
+
+	args := []string{"-m", t.modelPath, "-f", audioPath, "--no-timestamps", "--no-prints"}
+	if options.Language != "" {
+		args = append(args, "--language", options.Language)
+	}
+	if options.Prompt != "" {
+		args = append(args, "--prompt", options.Prompt)
+	}
+
+	cmd := exec.CommandContext(ctx, t.binary, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("whisper.cpp failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// fakeTranscriber returns a canned text for every clip, which keeps tests and
+// demos independent of any speech backend.
+type fakeTranscriber struct {
+	text string
+}
+
+func (t *fakeTranscriber) transcribe(context.Context, []byte, audioFormat, TranscriptionOptions) (string, error) {
+	return t.text, nil
+}
+
+// createAudioFormFile adds the clip with a file name and content type that
+// match its real container, since backends use them to pick a decoder.
+func createAudioFormFile(writer *multipart.Writer, format audioFormat) (io.Writer, error) {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="audio.`+format.extension+`"`)
+	header.Set("Content-Type", format.contentType)
+	return writer.CreatePart(header)
+}
+
+func doTranscriptionRequest(client *outboundClient, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	// Check for errors in the response
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Error json.RawMessage `json:"error"`
+		}
+		err := json.Unmarshal(respBody, &errorResponse)
+		if err != nil {
+			return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
+		}
+		return nil, fmt.Errorf("API request failed: %s", string(errorResponse.Error))
+	}
+
+	return respBody, nil
+}
+
+func parseTranscriptionResponse(respBody []byte) (string, error) {
+	var response struct {
+		Text string `json:"text"`
+	}
+	err := json.Unmarshal(respBody, &response)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse response: %v", err)
+	}
+	return strings.TrimSpace(response.Text), nil
+}
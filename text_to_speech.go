@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// maxSpeechTextLength is the input limit of the OpenAI speech API.
+const maxSpeechTextLength = 4096
+
+type TextToSpeech interface {
+	Synthesize(ctx context.Context, text string, language string) (SpeechAudio, error)
+}
+
+// SpeechAudio is a spoken text.
+type SpeechAudio struct {
+	weaver.AutoMarshal
+	Audio       []byte
+	ContentType string
+}
+
+type textToSpeechConfig struct {
+	// Backend is one of "openai" (default), "espeak" or "piper".
+	Backend string `toml:"backend"`
+	// BaseURL, Model and Voice configure an OpenAI compatible speech API.
+	BaseURL string `toml:"base_url"`
+	Model   string `toml:"model"`
+	Voice   string `toml:"voice"`
+	// Binary of espeak-ng or piper and the piper voice model.
+	Binary    string `toml:"binary"`
+	ModelPath string `toml:"model_path"`
+	// Outbound configures timeouts, retries and the circuit breaker of the
+	// openai backend.
+	Outbound outboundConfig `toml:"outbound"`
+}
+
+// Implementation of the TextToSpeech component.
+type textToSpeech struct {
+	weaver.Implements[TextToSpeech]
+	weaver.WithConfig[textToSpeechConfig]
+	client *outboundClient
+}
+
+func (t *textToSpeech) Init(context.Context) error {
+	t.client = newOutboundClient(t.Config().Outbound, t.Logger())
+	switch t.Config().Backend {
+	case "", "openai", "espeak":
+		return nil
+	case "piper":
+		if t.Config().ModelPath == "" {
+			return fmt.Errorf("piper backend needs model_path")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown text-to-speech backend %q", t.Config().Backend)
+	}
+}
+
+func (t *textToSpeech) Synthesize(ctx context.Context, text string, language string) (SpeechAudio, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return SpeechAudio{}, fmt.Errorf("nothing to read")
+	}
+	if len(text) > maxSpeechTextLength {
+		text = strings.ToValidUTF8(text[:maxSpeechTextLength], "")
+	}
+
+	t.Logger().Info("Synthesizing speech", "backend", t.Config().Backend, "length", len(text))
+
+	switch t.Config().Backend {
+	case "espeak":
+		return t.synthesizeWithEspeak(ctx, text, language)
+	case "piper":
+		return t.synthesizeWithPiper(ctx, text)
+	default:
+		return t.synthesizeWithOpenAI(ctx, text)
+	}
+}
+
+func (t *textToSpeech) synthesizeWithOpenAI(ctx context.Context, text string) (SpeechAudio, error) {
+	config := t.Config()
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	model := config.Model
+	if model == "" {
+		model = "tts-1"
+	}
+	voice := config.Voice
+	if voice == "" {
+		voice = "alloy"
+	}
+
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" && baseURL == defaultOpenAIBaseURL {
+		return SpeechAudio{}, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+	}
+
+	requestBody, err := json.Marshal(map[string]string{
+		"model":           model,
+		"voice":           voice,
+		"input":           text,
+		"response_format": "mp3",
+	})
+	if err != nil {
+		return SpeechAudio{}, err
+	}
+
+	url := strings.TrimSuffix(baseURL, "/") + "/audio/speech"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(requestBody))
+	if err != nil {
+		return SpeechAudio{}, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return SpeechAudio{}, err
+	}
+	defer resp.Body.Close()
+
+	audio, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return SpeechAudio{}, fmt.Errorf("failed to read response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return SpeechAudio{}, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(audio))
+	}
+
+	return SpeechAudio{Audio: audio, ContentType: "audio/mpeg"}, nil
+}
+
+func (t *textToSpeech) synthesizeWithEspeak(ctx context.Context, text string, language string) (SpeechAudio, error) {
+	binary := t.Config().Binary
+	if binary == "" {
+		binary = "espeak-ng"
+	}
+
+	args := []string{"--stdout"}
+	if language != "" {
+		args = append(args, "-v", language)
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdin = strings.NewReader(text)
+	return runSpeechCommand(cmd)
+}
+
+func (t *textToSpeech) synthesizeWithPiper(ctx context.Context, text string) (SpeechAudio, error) {
+	binary := t.Config().Binary
+	if binary == "" {
+		binary = "piper"
+	}
+
+	cmd := exec.CommandContext(ctx, binary, "--model", t.Config().ModelPath, "--output_file", "-")
+	cmd.Stdin = strings.NewReader(text)
+	return runSpeechCommand(cmd)
+}
+
+// runSpeechCommand runs a local engine that writes a WAV file to stdout.
+func runSpeechCommand(cmd *exec.Cmd) (SpeechAudio, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return SpeechAudio{}, fmt.Errorf("%s failed: %v: %s", cmd.Path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return SpeechAudio{Audio: stdout.Bytes(), ContentType: "audio/wav"}, nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+const usageFileName = "usage.jsonl"
+
+var (
+	ErrBudgetExceeded = errors.New("monthly LLM budget exceeded")
+	// ErrUserBudgetExceeded and ErrDocumentBudgetExceeded wrap
+	// ErrBudgetExceeded.
+	ErrUserBudgetExceeded     = fmt.Errorf("%w for user", ErrBudgetExceeded)
+	ErrDocumentBudgetExceeded = fmt.Errorf("%w for document", ErrBudgetExceeded)
+)
+
+type UsageLedger interface {
+	Record(ctx context.Context, record UsageRecord) (UsageRecord, error)
+	CheckBudget(ctx context.Context, user string, document string, estimate TokenUsage) error
+	Summarize(ctx context.Context, query UsageQuery) ([]UsageAggregate, error)
+}
+
+// TokenUsage is what a single GPT call used.
+type TokenUsage struct {
+	weaver.AutoMarshal
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"promptTokens"`
+	CompletionTokens int    `json:"completionTokens"`
+	// Cached is set when the answer came from the cache and used no tokens.
+	Cached bool `json:"cached,omitempty"`
+}
+
+// UsageRecord is one GPT call, priced when it is recorded.
+type UsageRecord struct {
+	weaver.AutoMarshal
+	Time     time.Time  `json:"time"`
+	User     string     `json:"user,omitempty"`
+	Document string     `json:"document,omitempty"`
+	Usage    TokenUsage `json:"usage"`
+	Cost     float64    `json:"cost"`
+}
+
+// UsageQuery selects the records to aggregate. GroupBy is "user",
+// "document" or "day", empty filters match everything.
+type UsageQuery struct {
+	weaver.AutoMarshal
+	GroupBy  string
+	From     time.Time
+	To       time.Time
+	User     string
+	Document string
+}
+
+// UsageAggregate sums up the records of one user, document or day.
+type UsageAggregate struct {
+	weaver.AutoMarshal
+	Key              string  `json:"key"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// modelPrice is the price of 1000 tokens.
+type modelPrice struct {
+	Prompt     float64 `toml:"prompt"`
+	Completion float64 `toml:"completion"`
+}
+
+type usageConfig struct {
+	// Prices per 1000 tokens by model, e.g. {"gpt-3.5-turbo" = {prompt =
+	// 0.0015, completion = 0.002}}. Calls of other models cost nothing.
+	Prices map[string]modelPrice `toml:"prices"`
+	// UserBudget and DocumentBudget limit the cost per calendar month, 0
+	// means no limit. UserBudgets overrides UserBudget for single users.
+	UserBudget     float64            `toml:"user_monthly_budget"`
+	DocumentBudget float64            `toml:"document_monthly_budget"`
+	UserBudgets    map[string]float64 `toml:"user_monthly_budgets"`
+}
+
+// Implementation of the UsageLedger component.
+type usageLedger struct {
+	weaver.Implements[UsageLedger]
+	weaver.WithConfig[usageConfig]
+	mu sync.Mutex
+}
+
+func (u *usageLedger) path() string {
+	return filepath.Join(workDirName, usageFileName)
+}
+
+// cost prices the tokens of a call by its model.
+func (u *usageLedger) cost(usage TokenUsage) float64 {
+	price := u.Config().Prices[usage.Model]
+	return (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1000
+}
+
+// Record prices a call and appends it to the ledger.
+func (u *usageLedger) Record(ctx context.Context, record UsageRecord) (UsageRecord, error) {
+	record.Cost = u.cost(record.Usage)
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return UsageRecord{}, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := os.MkdirAll(workDirName, 0755); err != nil {
+		return UsageRecord{}, err
+	}
+	file, err := os.OpenFile(u.path(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return UsageRecord{}, err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return UsageRecord{}, err
+	}
+
+	u.Logger().Info("Recorded LLM usage", "user", record.User, "document", record.Document, "model", record.Usage.Model,
+		"tokens", record.Usage.PromptTokens+record.Usage.CompletionTokens, "cost", record.Cost)
+	return record, nil
+}
+
+// CheckBudget returns ErrUserBudgetExceeded or ErrDocumentBudgetExceeded
+// once the cost of the current month reached a budget, or would exceed it
+// with the estimated usage of the next change. A change of a long document
+// makes a call per part, so checking the spending alone could overshoot the
+// budget by all of them.
+func (u *usageLedger) CheckBudget(ctx context.Context, user string, document string, estimate TokenUsage) error {
+	userBudget := u.Config().UserBudget
+	if budget, ok := u.Config().UserBudgets[user]; ok {
+		userBudget = budget
+	}
+	documentBudget := u.Config().DocumentBudget
+	if userBudget <= 0 && documentBudget <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	records, err := u.read(UsageQuery{From: monthStart})
+	if err != nil {
+		return err
+	}
+
+	var userCost, documentCost float64
+	for _, record := range records {
+		if record.User == user {
+			userCost += record.Cost
+		}
+		if record.Document == document {
+			documentCost += record.Cost
+		}
+	}
+
+	estimated := u.cost(estimate)
+	exceeds := func(cost float64, budget float64) bool {
+		return budget > 0 && (cost >= budget || cost+estimated > budget)
+	}
+	if exceeds(userCost, userBudget) {
+		return fmt.Errorf("%w: %s spent %.2f of %.2f, the change costs about %.2f", ErrUserBudgetExceeded, user, userCost, userBudget, estimated)
+	}
+	if exceeds(documentCost, documentBudget) {
+		return fmt.Errorf("%w: %s used %.2f of %.2f, the change costs about %.2f", ErrDocumentBudgetExceeded, document, documentCost, documentBudget, estimated)
+	}
+	return nil
+}
+
+// Summarize groups the matching records, sorted by key.
+func (u *usageLedger) Summarize(ctx context.Context, query UsageQuery) ([]UsageAggregate, error) {
+	key := map[string]func(UsageRecord) string{
+		"user":     func(r UsageRecord) string { return r.User },
+		"document": func(r UsageRecord) string { return r.Document },
+		"day":      func(r UsageRecord) string { return r.Time.Format("2006-01-02") },
+	}[query.GroupBy]
+	if key == nil {
+		return nil, fmt.Errorf("%w: group_by must be user, document or day", errInvalidRequest)
+	}
+
+	records, err := u.read(query)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates := make(map[string]*UsageAggregate)
+	for _, record := range records {
+		k := key(record)
+		aggregate, ok := aggregates[k]
+		if !ok {
+			aggregate = &UsageAggregate{Key: k}
+			aggregates[k] = aggregate
+		}
+		aggregate.Calls++
+		aggregate.PromptTokens += record.Usage.PromptTokens
+		aggregate.CompletionTokens += record.Usage.CompletionTokens
+		aggregate.Cost += record.Cost
+	}
+
+	result := []UsageAggregate{}
+	for _, aggregate := range aggregates {
+		result = append(result, *aggregate)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result, nil
+}
+
+// read returns the records that match the filters of a query.
+func (u *usageLedger) read(query UsageQuery) ([]UsageRecord, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	file, err := os.Open(u.path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []UsageRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record UsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue // skip a line cut off by a crash
+		}
+		switch {
+		case !query.From.IsZero() && record.Time.Before(query.From),
+			!query.To.IsZero() && !record.Time.Before(query.To),
+			query.User != "" && record.User != query.User,
+			query.Document != "" && record.Document != query.Document:
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
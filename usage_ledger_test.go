@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+func TestCheckBudgetCountsTheEstimatedChange(t *testing.T) {
+	inWorkspace(t, nil)
+	runner := weavertest.Local
+	runner.Config = `
+["sudocu/UsageLedger"]
+prices = { "gpt-3.5-turbo" = { prompt = 1.0, completion = 1.0 } }
+user_monthly_budget = 5.0
+document_monthly_budget = 3.0
+`
+	runner.Test(t, func(t *testing.T, ledger UsageLedger) {
+		ctx := context.Background()
+		tokens := func(n int) TokenUsage {
+			return TokenUsage{Model: "gpt-3.5-turbo", PromptTokens: n}
+		}
+		if _, err := ledger.Record(ctx, UsageRecord{User: "alice", Document: "invoice", Usage: tokens(2000)}); err != nil {
+			t.Fatal(err)
+		}
+
+		// 2 of 3 are spent on the document, a change of 1 fits
+		if err := ledger.CheckBudget(ctx, "alice", "invoice", tokens(1000)); err != nil {
+			t.Errorf("change within the budget: %v", err)
+		}
+		// A change in many parts would overshoot it
+		if err := ledger.CheckBudget(ctx, "alice", "invoice", tokens(1500)); !errors.Is(err, ErrDocumentBudgetExceeded) {
+			t.Errorf("got %v, want ErrDocumentBudgetExceeded", err)
+		}
+		if err := ledger.CheckBudget(ctx, "alice", "letter", tokens(3500)); !errors.Is(err, ErrUserBudgetExceeded) {
+			t.Errorf("got %v, want ErrUserBudgetExceeded", err)
+		}
+		// Unpriced models cost nothing
+		if err := ledger.CheckBudget(ctx, "alice", "invoice", TokenUsage{Model: "local", PromptTokens: 1e6}); err != nil {
+			t.Errorf("unpriced change: %v", err)
+		}
+	})
+}
+
+func TestEstimateUsageCountsEveryPart(t *testing.T) {
+	runner := weavertest.Local
+	runner.Config = `
+["sudocu/ChatGPTRepository"]
+context_tokens = 2048
+`
+	runner.Test(t, func(t *testing.T, chatGPT ChatGPTRepository) {
+		ctx := context.Background()
+		short, err := chatGPT.EstimateUsage(ctx, "= Letter\n\nHello\n", "fix typos")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		markup := longDocument() + longDocument() + longDocument()
+		long, err := chatGPT.EstimateUsage(ctx, markup, "fix typos")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if long.CompletionTokens < estimateTokens(markup) {
+			t.Errorf("estimated %d completion tokens for %d tokens of markup", long.CompletionTokens, estimateTokens(markup))
+		}
+		// Every part repeats the instructions and the prompt
+		overhead := short.PromptTokens - estimateTokens("= Letter\n\nHello\n")
+		if parts := (long.PromptTokens - estimateTokens(markup)) / overhead; parts < 2 {
+			t.Errorf("estimate counts the instructions %d times, want once per part", parts)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+var voicePromptIDPattern = regexp.MustCompile(`^[0-9]{8}_[0-9]{6}_[0-9a-f]{8}$`)
+
+type VoiceArchive interface {
+	Store(ctx context.Context, prompt VoicePrompt, audio []byte) (string, error)
+	List(ctx context.Context, document string) ([]VoicePrompt, error)
+	ReadAudio(ctx context.Context, id string) (VoicePrompt, []byte, error)
+}
+
+// VoicePrompt describes an archived clip and what it was transcribed to.
+type VoicePrompt struct {
+	weaver.AutoMarshal
+	ID         string `json:"id"`
+	Document   string `json:"document,omitempty"`
+	Transcript string `json:"transcript"`
+	Language   string `json:"language,omitempty"`
+	Format     string `json:"format"`
+	// DurationSeconds is the length of the clip, 0 if unknown.
+	DurationSeconds float64   `json:"durationSeconds"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type voiceArchiveConfig struct {
+	// Enabled turns archiving on, clips are discarded by default.
+	Enabled bool `toml:"enabled"`
+	// Dir is where clips are stored, "work/voice" by default.
+	Dir string `toml:"dir"`
+	// RetentionDays deletes clips older than that. 0 keeps them forever.
+	RetentionDays int `toml:"retention_days"`
+}
+
+// Implementation of the VoiceArchive component.
+type voiceArchive struct {
+	weaver.Implements[VoiceArchive]
+	weaver.WithConfig[voiceArchiveConfig]
+}
+
+func (v *voiceArchive) Init(context.Context) error {
+	if err := v.purge(); err != nil {
+		return err
+	}
+	if v.Config().RetentionDays > 0 {
+		go v.purgePeriodically(purgeInterval)
+	}
+	return nil
+}
+
+// purgeInterval is how often expired clips are deleted, so that they do not
+// outlive the retention period while no new clips are stored.
+const purgeInterval = time.Hour
+
+func (v *voiceArchive) purgePeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := v.purge(); err != nil {
+			v.Logger().Warn("Failed to purge voice archive", "err", err)
+		}
+	}
+}
+
+func (v *voiceArchive) dir() string {
+	if v.Config().Dir != "" {
+		return v.Config().Dir
+	}
+	return filepath.Join(workDirName, "voice")
+}
+
+func (v *voiceArchive) Store(ctx context.Context, prompt VoicePrompt, audio []byte) (string, error) {
+	if !v.Config().Enabled {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(v.dir(), 0700); err != nil {
+		return "", err
+	}
+
+	format, err := detectAudioFormat(audio)
+	if err != nil {
+		return "", err
+	}
+	prompt.Format = format.extension
+	prompt.DurationSeconds = audioDuration(ctx, audio, format).Seconds()
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	prompt.CreatedAt = time.Now()
+	prompt.ID = prompt.CreatedAt.Format("20060102_150405") + "_" + hex.EncodeToString(suffix)
+
+	metadata, err := json.MarshalIndent(prompt, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(v.dir(), prompt.ID+"."+prompt.Format), audio, 0600); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(v.dir(), prompt.ID+".json"), metadata, 0600); err != nil {
+		return "", err
+	}
+
+	if err := v.purge(); err != nil {
+		v.Logger().Warn("Failed to purge voice archive", "err", err)
+	}
+
+	return prompt.ID, nil
+}
+
+// List returns the archived prompts of a document, newest first. An empty
+// document lists all prompts.
+func (v *voiceArchive) List(ctx context.Context, document string) ([]VoicePrompt, error) {
+	prompts, err := v.readAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []VoicePrompt
+	for _, prompt := range prompts {
+		if document == "" || prompt.Document == document {
+			result = append(result, prompt)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (v *voiceArchive) ReadAudio(ctx context.Context, id string) (VoicePrompt, []byte, error) {
+	if !voicePromptIDPattern.MatchString(id) {
+		return VoicePrompt{}, nil, fmt.Errorf("invalid voice prompt id %q", id)
+	}
+
+	prompt, err := v.read(filepath.Join(v.dir(), id+".json"))
+	if err != nil {
+		return VoicePrompt{}, nil, err
+	}
+
+	audio, err := ioutil.ReadFile(filepath.Join(v.dir(), id+"."+prompt.Format))
+	if err != nil {
+		return VoicePrompt{}, nil, err
+	}
+	return prompt, audio, nil
+}
+
+func (v *voiceArchive) readAll() ([]VoicePrompt, error) {
+	files, err := ioutil.ReadDir(v.dir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var prompts []VoicePrompt
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		prompt, err := v.read(filepath.Join(v.dir(), file.Name()))
+		if err != nil {
+			v.Logger().Warn("Skipping unreadable voice prompt", "file", file.Name(), "err", err)
+			continue
+		}
+		prompts = append(prompts, prompt)
+	}
+	return prompts, nil
+}
+
+func (v *voiceArchive) read(path string) (VoicePrompt, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return VoicePrompt{}, err
+	}
+
+	var prompt struct {
+		VoicePrompt
+		// Duration in nanoseconds was written by earlier versions
+		Duration time.Duration `json:"duration"`
+	}
+	if err := json.Unmarshal(data, &prompt); err != nil {
+		return VoicePrompt{}, err
+	}
+	if prompt.DurationSeconds == 0 {
+		prompt.DurationSeconds = prompt.Duration.Seconds()
+	}
+	return prompt.VoicePrompt, nil
+}
+
+// purge deletes the prompts that are older than the retention period.
+func (v *voiceArchive) purge() error {
+	if v.Config().RetentionDays <= 0 {
+		return nil
+	}
+
+	prompts, err := v.readAll()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -v.Config().RetentionDays)
+	for _, prompt := range prompts {
+		if prompt.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(v.dir(), prompt.ID+"."+prompt.Format)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Remove(filepath.Join(v.dir(), prompt.ID+".json")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
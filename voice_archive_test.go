@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestVoiceArchiveReadsDurationInSeconds(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"current.json": `{"id": "20240102_030405_0011aabb", "format": "wav", "durationSeconds": 2.5}`,
+		"legacy.json":  `{"id": "20240102_030405_0011aabb", "format": "wav", "duration": 2500000000}`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		prompt, err := (&voiceArchive{}).read(filepath.Join(dir, name))
+		if err != nil || prompt.DurationSeconds != 2.5 {
+			t.Errorf("%s: got %v seconds, %v, want 2.5", name, prompt.DurationSeconds, err)
+		}
+	}
+}
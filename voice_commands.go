@@ -0,0 +1,103 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	CommandUndo     = "undo"
+	CommandPrevious = "previous"
+	CommandDownload = "download"
+	CommandSwitch   = "switch"
+	CommandRead     = "read"
+	CommandEdit     = "edit"
+)
+
+// VoiceCommand is the intent recognized in a transcribed prompt. Prompts that
+// are no command are content edits for the ChatGPTRepository.
+type VoiceCommand struct {
+	Intent   string `json:"intent"`
+	Document string `json:"document,omitempty"`
+}
+
+// The patterns are matched against the whole, normalized prompt, so a longer
+// instruction that merely contains one of the words stays a content edit.
+var voiceCommandPatterns = []struct {
+	intent  string
+	pattern *regexp.Regexp
+}{
+	{CommandUndo, regexp.MustCompile(`^(undo|undo (that|it|this|the last change)|revert( that| the last change)?|(mach(e)? )?(das |es )?rückgängig( machen)?|letzte änderung rückgängig( machen)?|zurücknehmen)$`)},
+	{CommandPrevious, regexp.MustCompile(`^((show|display|open)( me)? (the )?(previous|last|older) (version|variant)|(zeig(e)?|öffne)( mir)? (die )?(vorherige|letzte|alte|ältere) (version|fassung|variante))$`)},
+	{CommandDownload, regexp.MustCompile(`^((download|save)( the)?( pdf| document| file)?|(pdf |dokument |datei )?(herunterladen|runterladen|speichern)|lade (das |die )?(pdf|dokument|datei)? ?herunter)$`)},
+	{CommandRead, regexp.MustCompile(`^(read (it|this|that|the document)( back| aloud| out loud)?|read back|(lies|lese) (es |das |das dokument |mir das )?vor|vorlesen|(das )?dokument vorlesen)$`)},
+	{CommandSwitch, regexp.MustCompile(`^(switch to|go to|open|show me|wechsel(e)? (zu(m|r)?|auf)|geh(e)? zu(m|r)?|öffne|zeig(e)?( mir)?) (the |das |die |den )?(document |dokument )?(?P<document>.+)$`)},
+}
+
+// parseVoiceCommand recognizes German and English commands in a prompt. The
+// documents function maps file names to their titles and resolves the target
+// of a switch command. It is only called for prompts that look like one, as
+// reading every document is slow; prompts that name no known document are
+// edits.
+func parseVoiceCommand(prompt string, documents func() (map[string]string, error)) (VoiceCommand, error) {
+	normalized := normalizeCommand(prompt)
+
+	for _, command := range voiceCommandPatterns {
+		match := command.pattern.FindStringSubmatch(normalized)
+		if match == nil {
+			continue
+		}
+
+		if command.intent != CommandSwitch {
+			return VoiceCommand{Intent: command.intent}, nil
+		}
+
+		titles, err := documents()
+		if err != nil {
+			return VoiceCommand{}, err
+		}
+		name := match[command.pattern.SubexpIndex("document")]
+		if document, ok := findDocument(name, titles); ok {
+			return VoiceCommand{Intent: CommandSwitch, Document: document}, nil
+		}
+	}
+
+	return VoiceCommand{Intent: CommandEdit}, nil
+}
+
+func normalizeCommand(prompt string) string {
+	prompt = strings.ToLower(strings.TrimSpace(prompt))
+	prompt = strings.Trim(prompt, ".!?,;: ")
+	prompt = strings.TrimPrefix(prompt, "bitte ")
+	prompt = strings.TrimSuffix(prompt, " bitte")
+	prompt = strings.TrimPrefix(prompt, "please ")
+	prompt = strings.TrimSuffix(prompt, " please")
+	// "undo it, please" leaves a comma behind
+	prompt = strings.Trim(prompt, ".!?,;: ")
+	return strings.Join(strings.Fields(prompt), " ")
+}
+
+// findDocument matches a spoken name against file names, where underscores
+// are read as spaces, and document titles.
+func findDocument(name string, documents map[string]string) (string, bool) {
+	name = normalizeCommand(name)
+	for fileName, title := range documents {
+		if name == strings.ToLower(strings.ReplaceAll(fileName, "_", " ")) || name == strings.ToLower(fileName) {
+			return fileName, true
+		}
+		if title != "" && name == normalizeCommand(title) {
+			return fileName, true
+		}
+	}
+	return "", false
+}
+
+// documentTitle returns the level 0 heading of a document.
+func documentTitle(markup []byte) string {
+	for _, line := range strings.Split(string(markup), "\n") {
+		if strings.HasPrefix(line, "= ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "= "))
+		}
+	}
+	return ""
+}
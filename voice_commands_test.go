@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+var commandDocuments = map[string]string{
+	"invoice":       "Rechnung",
+	"friend_letter": "Letter to a friend",
+}
+
+func TestParseVoiceCommand(t *testing.T) {
+	tests := []struct {
+		prompt   string
+		intent   string
+		document string
+	}{
+		// English
+		{"Undo", CommandUndo, ""},
+		{"undo the last change.", CommandUndo, ""},
+		{"Please revert that", CommandUndo, ""},
+		{"show me the previous version", CommandPrevious, ""},
+		{"Open the older variant!", CommandPrevious, ""},
+		{"download the PDF", CommandDownload, ""},
+		{"save", CommandDownload, ""},
+		{"read it back", CommandRead, ""},
+		{"Read the document aloud, please", CommandRead, ""},
+		{"switch to invoice", CommandSwitch, "invoice"},
+		{"go to friend letter", CommandSwitch, "friend_letter"},
+		{"open the document Letter to a friend", CommandSwitch, "friend_letter"},
+
+		// German
+		{"Mach das rückgängig", CommandUndo, ""},
+		{"letzte Änderung rückgängig machen", CommandUndo, ""},
+		{"zurücknehmen bitte", CommandUndo, ""},
+		{"Zeig mir die vorherige Version", CommandPrevious, ""},
+		{"öffne die alte Fassung", CommandPrevious, ""},
+		{"PDF herunterladen", CommandDownload, ""},
+		{"lade das Dokument herunter", CommandDownload, ""},
+		{"Lies es vor", CommandRead, ""},
+		{"Dokument vorlesen", CommandRead, ""},
+		{"wechsel zur Rechnung", CommandSwitch, "invoice"},
+		{"Geh zu friend_letter", CommandSwitch, "friend_letter"},
+
+		// Edits that merely contain a command word
+		{"undo the discount in line 3 and add 5% instead", CommandEdit, ""},
+		{"add a line: please download the attachment", CommandEdit, ""},
+		{"read the terms and shorten them", CommandEdit, ""},
+		{"Mach die Überschrift rückgängig fett", CommandEdit, ""},
+		{"switch to formal tone", CommandEdit, ""},
+		{"öffne den Vertrag", CommandEdit, ""},
+		{"", CommandEdit, ""},
+	}
+	for _, test := range tests {
+		command, err := parseVoiceCommand(test.prompt, func() (map[string]string, error) { return commandDocuments, nil })
+		if err != nil || command.Intent != test.intent || command.Document != test.document {
+			t.Errorf("parseVoiceCommand(%q) = %+v, %v, want %s %s", test.prompt, command, err, test.intent, test.document)
+		}
+	}
+}
+
+func TestParseVoiceCommandReadsDocumentsOnlyForSwitch(t *testing.T) {
+	read := 0
+	documents := func() (map[string]string, error) {
+		read++
+		return commandDocuments, nil
+	}
+	for _, prompt := range []string{"undo", "add a line item for travel", "show the previous version", "lies es vor"} {
+		if _, err := parseVoiceCommand(prompt, documents); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if read != 0 {
+		t.Errorf("documents were read %d times for prompts that are no switch", read)
+	}
+
+	failing := errors.New("disk on fire")
+	if _, err := parseVoiceCommand("switch to invoice", func() (map[string]string, error) { return nil, failing }); !errors.Is(err, failing) {
+		t.Errorf("got %v, want the error reading the documents", err)
+	}
+}
@@ -6,11 +6,13 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"reflect"
+	"time"
 )
 
 var _ codegen.LatestVersion = codegen.Version[[0][17]struct{}](`
@@ -39,31 +41,76 @@ func init() {
 		Iface: reflect.TypeOf((*ADocRepository)(nil)).Elem(),
 		Impl:  reflect.TypeOf(aDocRepository{}),
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return aDocRepository_local_stub{impl: impl.(ADocRepository), tracer: tracer, getFilesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetFiles", Remote: false}), readFileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ReadFile", Remote: false}), saveVariantForFileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "SaveVariantForFile", Remote: false})}
+			return aDocRepository_local_stub{impl: impl.(ADocRepository), tracer: tracer, archiveMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Archive", Remote: false}), authorizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Authorize", Remote: false}), finalizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Finalize", Remote: false}), getAccessMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetAccess", Remote: false}), getFilesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetFiles", Remote: false}), getRoleMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetRole", Remote: false}), getStateMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetState", Remote: false}), getVisibleFilesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetVisibleFiles", Remote: false}), listVersionsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ListVersions", Remote: false}), readFileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ReadFile", Remote: false}), readFinalPDFMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ReadFinalPDF", Remote: false}), readVersionMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ReadVersion", Remote: false}), saveVariantForFileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "SaveVariantForFile", Remote: false}), shareMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Share", Remote: false}), undoMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Undo", Remote: false})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return aDocRepository_client_stub{stub: stub, getFilesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetFiles", Remote: true}), readFileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ReadFile", Remote: true}), saveVariantForFileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "SaveVariantForFile", Remote: true})}
+			return aDocRepository_client_stub{stub: stub, archiveMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Archive", Remote: true}), authorizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Authorize", Remote: true}), finalizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Finalize", Remote: true}), getAccessMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetAccess", Remote: true}), getFilesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetFiles", Remote: true}), getRoleMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetRole", Remote: true}), getStateMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetState", Remote: true}), getVisibleFilesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "GetVisibleFiles", Remote: true}), listVersionsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ListVersions", Remote: true}), readFileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ReadFile", Remote: true}), readFinalPDFMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ReadFinalPDF", Remote: true}), readVersionMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "ReadVersion", Remote: true}), saveVariantForFileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "SaveVariantForFile", Remote: true}), shareMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Share", Remote: true}), undoMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ADocRepository", Method: "Undo", Remote: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return aDocRepository_server_stub{impl: impl.(ADocRepository), addLoad: addLoad}
 		},
 		RefData: "",
 	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/Authenticator",
+		Iface: reflect.TypeOf((*Authenticator)(nil)).Elem(),
+		Impl:  reflect.TypeOf(authenticator{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return authenticator_local_stub{impl: impl.(Authenticator), tracer: tracer, authenticateMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "Authenticate", Remote: false}), issueSessionMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "IssueSession", Remote: false}), oIDCEnabledMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "OIDCEnabled", Remote: false}), oIDCExchangeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "OIDCExchange", Remote: false}), oIDCLoginURLMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "OIDCLoginURL", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return authenticator_client_stub{stub: stub, authenticateMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "Authenticate", Remote: true}), issueSessionMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "IssueSession", Remote: true}), oIDCEnabledMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "OIDCEnabled", Remote: true}), oIDCExchangeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "OIDCExchange", Remote: true}), oIDCLoginURLMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/Authenticator", Method: "OIDCLoginURL", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return authenticator_server_stub{impl: impl.(Authenticator), addLoad: addLoad}
+		},
+		RefData: "",
+	})
 	codegen.Register(codegen.Registration{
 		Name:  "sudocu/ChatGPTRepository",
 		Iface: reflect.TypeOf((*ChatGPTRepository)(nil)).Elem(),
 		Impl:  reflect.TypeOf(chatGPTRepository{}),
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return chatGPTRepository_local_stub{impl: impl.(ChatGPTRepository), tracer: tracer, changeMarkupMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ChatGPTRepository", Method: "ChangeMarkup", Remote: false})}
+			return chatGPTRepository_local_stub{impl: impl.(ChatGPTRepository), tracer: tracer, changeMarkupMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ChatGPTRepository", Method: "ChangeMarkup", Remote: false}), estimateUsageMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ChatGPTRepository", Method: "EstimateUsage", Remote: false})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return chatGPTRepository_client_stub{stub: stub, changeMarkupMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ChatGPTRepository", Method: "ChangeMarkup", Remote: true})}
+			return chatGPTRepository_client_stub{stub: stub, changeMarkupMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ChatGPTRepository", Method: "ChangeMarkup", Remote: true}), estimateUsageMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ChatGPTRepository", Method: "EstimateUsage", Remote: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return chatGPTRepository_server_stub{impl: impl.(ChatGPTRepository), addLoad: addLoad}
 		},
 		RefData: "",
 	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/InvoiceService",
+		Iface: reflect.TypeOf((*InvoiceService)(nil)).Elem(),
+		Impl:  reflect.TypeOf(invoiceService{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return invoiceService_local_stub{impl: impl.(InvoiceService), tracer: tracer, checkMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/InvoiceService", Method: "Check", Remote: false}), reconcileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/InvoiceService", Method: "Reconcile", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return invoiceService_client_stub{stub: stub, checkMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/InvoiceService", Method: "Check", Remote: true}), reconcileMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/InvoiceService", Method: "Reconcile", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return invoiceService_server_stub{impl: impl.(InvoiceService), addLoad: addLoad}
+		},
+		RefData: "",
+	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/MailMerger",
+		Iface: reflect.TypeOf((*MailMerger)(nil)).Elem(),
+		Impl:  reflect.TypeOf(mailMerger{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return mailMerger_local_stub{impl: impl.(MailMerger), tracer: tracer, mergeToFolderMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/MailMerger", Method: "MergeToFolder", Remote: false}), mergeToZipMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/MailMerger", Method: "MergeToZip", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return mailMerger_client_stub{stub: stub, mergeToFolderMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/MailMerger", Method: "MergeToFolder", Remote: true}), mergeToZipMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/MailMerger", Method: "MergeToZip", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return mailMerger_server_stub{impl: impl.(MailMerger), addLoad: addLoad}
+		},
+		RefData: "⟦268f2e8d:wEaVeReDgE:sudocu/MailMerger→sudocu/ADocRepository⟧\n⟦c2254b67:wEaVeReDgE:sudocu/MailMerger→sudocu/PDFGenerator⟧\n⟦fd00daf8:wEaVeReDgE:sudocu/MailMerger→sudocu/InvoiceService⟧\n⟦284d0096:wEaVeReDgE:sudocu/MailMerger→sudocu/SequenceService⟧\n",
+	})
 	codegen.Register(codegen.Registration{
 		Name:      "github.com/ServiceWeaver/weaver/Main",
 		Iface:     reflect.TypeOf((*weaver.Main)(nil)).Elem(),
@@ -76,23 +123,68 @@ func init() {
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return main_server_stub{impl: impl.(weaver.Main), addLoad: addLoad}
 		},
-		RefData: "⟦9fd5554a:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/PDFGenerator⟧\n⟦f9992206:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/ADocRepository⟧\n⟦2a7c5efa:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/ChatGPTRepository⟧\n⟦fbea1504:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/SpeechRepository⟧\n⟦2248fb79:wEaVeRlIsTeNeRs:github.com/ServiceWeaver/weaver/Main→listener⟧\n",
+		RefData: "⟦9fd5554a:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/PDFGenerator⟧\n⟦f9992206:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/ADocRepository⟧\n⟦2a7c5efa:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/ChatGPTRepository⟧\n⟦fbea1504:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/SpeechRepository⟧\n⟦f7578c7b:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/MailMerger⟧\n⟦5502cc61:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/InvoiceService⟧\n⟦cd2472b7:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/SequenceService⟧\n⟦067a1e1f:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/TextToSpeech⟧\n⟦aba5e84c:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/VoiceArchive⟧\n⟦c7714e57:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/Authenticator⟧\n⟦19c5f788:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/ShareLinks⟧\n⟦c8e8af40:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/UsageLedger⟧\n⟦ce5d5f09:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→sudocu/PromptTemplates⟧\n⟦2248fb79:wEaVeRlIsTeNeRs:github.com/ServiceWeaver/weaver/Main→listener⟧\n",
 	})
 	codegen.Register(codegen.Registration{
 		Name:  "sudocu/PDFGenerator",
 		Iface: reflect.TypeOf((*PDFGenerator)(nil)).Elem(),
 		Impl:  reflect.TypeOf(pdfGenerator{}),
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return pDFGenerator_local_stub{impl: impl.(PDFGenerator), tracer: tracer, generatePDFMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PDFGenerator", Method: "GeneratePDF", Remote: false})}
+			return pDFGenerator_local_stub{impl: impl.(PDFGenerator), tracer: tracer, generatePDFMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PDFGenerator", Method: "GeneratePDF", Remote: false}), guardMarkupMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PDFGenerator", Method: "GuardMarkup", Remote: false})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return pDFGenerator_client_stub{stub: stub, generatePDFMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PDFGenerator", Method: "GeneratePDF", Remote: true})}
+			return pDFGenerator_client_stub{stub: stub, generatePDFMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PDFGenerator", Method: "GeneratePDF", Remote: true}), guardMarkupMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PDFGenerator", Method: "GuardMarkup", Remote: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return pDFGenerator_server_stub{impl: impl.(PDFGenerator), addLoad: addLoad}
 		},
 		RefData: "",
 	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/PromptTemplates",
+		Iface: reflect.TypeOf((*PromptTemplates)(nil)).Elem(),
+		Impl:  reflect.TypeOf(promptTemplates{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return promptTemplates_local_stub{impl: impl.(PromptTemplates), tracer: tracer, deleteMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PromptTemplates", Method: "Delete", Remote: false}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PromptTemplates", Method: "Get", Remote: false}), listMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PromptTemplates", Method: "List", Remote: false}), saveMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PromptTemplates", Method: "Save", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return promptTemplates_client_stub{stub: stub, deleteMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PromptTemplates", Method: "Delete", Remote: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PromptTemplates", Method: "Get", Remote: true}), listMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PromptTemplates", Method: "List", Remote: true}), saveMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/PromptTemplates", Method: "Save", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return promptTemplates_server_stub{impl: impl.(PromptTemplates), addLoad: addLoad}
+		},
+		RefData: "",
+	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/SequenceService",
+		Iface: reflect.TypeOf((*SequenceService)(nil)).Elem(),
+		Impl:  reflect.TypeOf(sequenceService{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return sequenceService_local_stub{impl: impl.(SequenceService), tracer: tracer, commitMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/SequenceService", Method: "Commit", Remote: false}), fillPlaceholdersMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/SequenceService", Method: "FillPlaceholders", Remote: false}), nextMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/SequenceService", Method: "Next", Remote: false}), releaseMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/SequenceService", Method: "Release", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return sequenceService_client_stub{stub: stub, commitMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/SequenceService", Method: "Commit", Remote: true}), fillPlaceholdersMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/SequenceService", Method: "FillPlaceholders", Remote: true}), nextMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/SequenceService", Method: "Next", Remote: true}), releaseMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/SequenceService", Method: "Release", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return sequenceService_server_stub{impl: impl.(SequenceService), addLoad: addLoad}
+		},
+		RefData: "",
+	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/ShareLinks",
+		Iface: reflect.TypeOf((*ShareLinks)(nil)).Elem(),
+		Impl:  reflect.TypeOf(shareLinks{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return shareLinks_local_stub{impl: impl.(ShareLinks), tracer: tracer, createMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "Create", Remote: false}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "Get", Remote: false}), listMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "List", Remote: false}), resolveMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "Resolve", Remote: false}), revokeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "Revoke", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return shareLinks_client_stub{stub: stub, createMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "Create", Remote: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "Get", Remote: true}), listMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "List", Remote: true}), resolveMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "Resolve", Remote: true}), revokeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/ShareLinks", Method: "Revoke", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return shareLinks_server_stub{impl: impl.(ShareLinks), addLoad: addLoad}
+		},
+		RefData: "",
+	})
 	codegen.Register(codegen.Registration{
 		Name:  "sudocu/SpeechRepository",
 		Iface: reflect.TypeOf((*SpeechRepository)(nil)).Elem(),
@@ -108,43 +200,118 @@ func init() {
 		},
 		RefData: "",
 	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/TextToSpeech",
+		Iface: reflect.TypeOf((*TextToSpeech)(nil)).Elem(),
+		Impl:  reflect.TypeOf(textToSpeech{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return textToSpeech_local_stub{impl: impl.(TextToSpeech), tracer: tracer, synthesizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/TextToSpeech", Method: "Synthesize", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return textToSpeech_client_stub{stub: stub, synthesizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/TextToSpeech", Method: "Synthesize", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return textToSpeech_server_stub{impl: impl.(TextToSpeech), addLoad: addLoad}
+		},
+		RefData: "",
+	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/UsageLedger",
+		Iface: reflect.TypeOf((*UsageLedger)(nil)).Elem(),
+		Impl:  reflect.TypeOf(usageLedger{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return usageLedger_local_stub{impl: impl.(UsageLedger), tracer: tracer, checkBudgetMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/UsageLedger", Method: "CheckBudget", Remote: false}), recordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/UsageLedger", Method: "Record", Remote: false}), summarizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/UsageLedger", Method: "Summarize", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return usageLedger_client_stub{stub: stub, checkBudgetMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/UsageLedger", Method: "CheckBudget", Remote: true}), recordMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/UsageLedger", Method: "Record", Remote: true}), summarizeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/UsageLedger", Method: "Summarize", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return usageLedger_server_stub{impl: impl.(UsageLedger), addLoad: addLoad}
+		},
+		RefData: "",
+	})
+	codegen.Register(codegen.Registration{
+		Name:  "sudocu/VoiceArchive",
+		Iface: reflect.TypeOf((*VoiceArchive)(nil)).Elem(),
+		Impl:  reflect.TypeOf(voiceArchive{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return voiceArchive_local_stub{impl: impl.(VoiceArchive), tracer: tracer, listMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/VoiceArchive", Method: "List", Remote: false}), readAudioMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/VoiceArchive", Method: "ReadAudio", Remote: false}), storeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/VoiceArchive", Method: "Store", Remote: false})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return voiceArchive_client_stub{stub: stub, listMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/VoiceArchive", Method: "List", Remote: true}), readAudioMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/VoiceArchive", Method: "ReadAudio", Remote: true}), storeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "sudocu/VoiceArchive", Method: "Store", Remote: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return voiceArchive_server_stub{impl: impl.(VoiceArchive), addLoad: addLoad}
+		},
+		RefData: "",
+	})
 }
 
 // weaver.InstanceOf checks.
 var _ weaver.InstanceOf[ADocRepository] = (*aDocRepository)(nil)
+var _ weaver.InstanceOf[Authenticator] = (*authenticator)(nil)
 var _ weaver.InstanceOf[ChatGPTRepository] = (*chatGPTRepository)(nil)
+var _ weaver.InstanceOf[InvoiceService] = (*invoiceService)(nil)
+var _ weaver.InstanceOf[MailMerger] = (*mailMerger)(nil)
 var _ weaver.InstanceOf[weaver.Main] = (*app)(nil)
 var _ weaver.InstanceOf[PDFGenerator] = (*pdfGenerator)(nil)
+var _ weaver.InstanceOf[PromptTemplates] = (*promptTemplates)(nil)
+var _ weaver.InstanceOf[SequenceService] = (*sequenceService)(nil)
+var _ weaver.InstanceOf[ShareLinks] = (*shareLinks)(nil)
 var _ weaver.InstanceOf[SpeechRepository] = (*speechRepository)(nil)
+var _ weaver.InstanceOf[TextToSpeech] = (*textToSpeech)(nil)
+var _ weaver.InstanceOf[UsageLedger] = (*usageLedger)(nil)
+var _ weaver.InstanceOf[VoiceArchive] = (*voiceArchive)(nil)
 
 // weaver.Router checks.
 var _ weaver.Unrouted = (*aDocRepository)(nil)
+var _ weaver.Unrouted = (*authenticator)(nil)
 var _ weaver.Unrouted = (*chatGPTRepository)(nil)
+var _ weaver.Unrouted = (*invoiceService)(nil)
+var _ weaver.Unrouted = (*mailMerger)(nil)
 var _ weaver.Unrouted = (*app)(nil)
 var _ weaver.Unrouted = (*pdfGenerator)(nil)
+var _ weaver.Unrouted = (*promptTemplates)(nil)
+var _ weaver.Unrouted = (*sequenceService)(nil)
+var _ weaver.Unrouted = (*shareLinks)(nil)
 var _ weaver.Unrouted = (*speechRepository)(nil)
+var _ weaver.Unrouted = (*textToSpeech)(nil)
+var _ weaver.Unrouted = (*usageLedger)(nil)
+var _ weaver.Unrouted = (*voiceArchive)(nil)
 
 // Local stub implementations.
 
 type aDocRepository_local_stub struct {
 	impl                      ADocRepository
 	tracer                    trace.Tracer
+	archiveMetrics            *codegen.MethodMetrics
+	authorizeMetrics          *codegen.MethodMetrics
+	finalizeMetrics           *codegen.MethodMetrics
+	getAccessMetrics          *codegen.MethodMetrics
 	getFilesMetrics           *codegen.MethodMetrics
+	getRoleMetrics            *codegen.MethodMetrics
+	getStateMetrics           *codegen.MethodMetrics
+	getVisibleFilesMetrics    *codegen.MethodMetrics
+	listVersionsMetrics       *codegen.MethodMetrics
 	readFileMetrics           *codegen.MethodMetrics
+	readFinalPDFMetrics       *codegen.MethodMetrics
+	readVersionMetrics        *codegen.MethodMetrics
 	saveVariantForFileMetrics *codegen.MethodMetrics
+	shareMetrics              *codegen.MethodMetrics
+	undoMetrics               *codegen.MethodMetrics
 }
 
 // Check that aDocRepository_local_stub implements the ADocRepository interface.
 var _ ADocRepository = (*aDocRepository_local_stub)(nil)
 
-func (s aDocRepository_local_stub) GetFiles(ctx context.Context) (r0 []string, err error) {
+func (s aDocRepository_local_stub) Archive(ctx context.Context, a0 string) (r0 DocumentState, err error) {
 	// Update metrics.
-	begin := s.getFilesMetrics.Begin()
-	defer func() { s.getFilesMetrics.End(begin, err != nil, 0, 0) }()
+	begin := s.archiveMetrics.Begin()
+	defer func() { s.archiveMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.GetFiles", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.Archive", trace.WithSpanKind(trace.SpanKindInternal))
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -154,17 +321,17 @@ func (s aDocRepository_local_stub) GetFiles(ctx context.Context) (r0 []string, e
 		}()
 	}
 
-	return s.impl.GetFiles(ctx)
+	return s.impl.Archive(ctx, a0)
 }
 
-func (s aDocRepository_local_stub) ReadFile(ctx context.Context, a0 string) (r0 []byte, err error) {
+func (s aDocRepository_local_stub) Authorize(ctx context.Context, a0 string, a1 string, a2 string) (err error) {
 	// Update metrics.
-	begin := s.readFileMetrics.Begin()
-	defer func() { s.readFileMetrics.End(begin, err != nil, 0, 0) }()
+	begin := s.authorizeMetrics.Begin()
+	defer func() { s.authorizeMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.ReadFile", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.Authorize", trace.WithSpanKind(trace.SpanKindInternal))
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -174,17 +341,17 @@ func (s aDocRepository_local_stub) ReadFile(ctx context.Context, a0 string) (r0
 		}()
 	}
 
-	return s.impl.ReadFile(ctx, a0)
+	return s.impl.Authorize(ctx, a0, a1, a2)
 }
 
-func (s aDocRepository_local_stub) SaveVariantForFile(ctx context.Context, a0 string, a1 []byte) (err error) {
+func (s aDocRepository_local_stub) Finalize(ctx context.Context, a0 string, a1 []byte, a2 []byte) (r0 DocumentState, err error) {
 	// Update metrics.
-	begin := s.saveVariantForFileMetrics.Begin()
-	defer func() { s.saveVariantForFileMetrics.End(begin, err != nil, 0, 0) }()
+	begin := s.finalizeMetrics.Begin()
+	defer func() { s.finalizeMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.SaveVariantForFile", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.Finalize", trace.WithSpanKind(trace.SpanKindInternal))
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -194,26 +361,17 @@ func (s aDocRepository_local_stub) SaveVariantForFile(ctx context.Context, a0 st
 		}()
 	}
 
-	return s.impl.SaveVariantForFile(ctx, a0, a1)
-}
-
-type chatGPTRepository_local_stub struct {
-	impl                ChatGPTRepository
-	tracer              trace.Tracer
-	changeMarkupMetrics *codegen.MethodMetrics
+	return s.impl.Finalize(ctx, a0, a1, a2)
 }
 
-// Check that chatGPTRepository_local_stub implements the ChatGPTRepository interface.
-var _ ChatGPTRepository = (*chatGPTRepository_local_stub)(nil)
-
-func (s chatGPTRepository_local_stub) ChangeMarkup(ctx context.Context, a0 string, a1 string) (r0 []byte, err error) {
+func (s aDocRepository_local_stub) GetAccess(ctx context.Context, a0 string) (r0 AccessList, err error) {
 	// Update metrics.
-	begin := s.changeMarkupMetrics.Begin()
-	defer func() { s.changeMarkupMetrics.End(begin, err != nil, 0, 0) }()
+	begin := s.getAccessMetrics.Begin()
+	defer func() { s.getAccessMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.ChatGPTRepository.ChangeMarkup", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.GetAccess", trace.WithSpanKind(trace.SpanKindInternal))
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -223,34 +381,37 @@ func (s chatGPTRepository_local_stub) ChangeMarkup(ctx context.Context, a0 strin
 		}()
 	}
 
-	return s.impl.ChangeMarkup(ctx, a0, a1)
-}
-
-type main_local_stub struct {
-	impl   weaver.Main
-	tracer trace.Tracer
+	return s.impl.GetAccess(ctx, a0)
 }
 
-// Check that main_local_stub implements the weaver.Main interface.
-var _ weaver.Main = (*main_local_stub)(nil)
+func (s aDocRepository_local_stub) GetFiles(ctx context.Context) (r0 []string, err error) {
+	// Update metrics.
+	begin := s.getFilesMetrics.Begin()
+	defer func() { s.getFilesMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.GetFiles", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
 
-type pDFGenerator_local_stub struct {
-	impl               PDFGenerator
-	tracer             trace.Tracer
-	generatePDFMetrics *codegen.MethodMetrics
+	return s.impl.GetFiles(ctx)
 }
 
-// Check that pDFGenerator_local_stub implements the PDFGenerator interface.
-var _ PDFGenerator = (*pDFGenerator_local_stub)(nil)
-
-func (s pDFGenerator_local_stub) GeneratePDF(ctx context.Context, a0 []byte) (r0 []byte, err error) {
+func (s aDocRepository_local_stub) GetRole(ctx context.Context, a0 string, a1 string) (r0 string, err error) {
 	// Update metrics.
-	begin := s.generatePDFMetrics.Begin()
-	defer func() { s.generatePDFMetrics.End(begin, err != nil, 0, 0) }()
+	begin := s.getRoleMetrics.Begin()
+	defer func() { s.getRoleMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.PDFGenerator.GeneratePDF", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.GetRole", trace.WithSpanKind(trace.SpanKindInternal))
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -260,26 +421,17 @@ func (s pDFGenerator_local_stub) GeneratePDF(ctx context.Context, a0 []byte) (r0
 		}()
 	}
 
-	return s.impl.GeneratePDF(ctx, a0)
-}
-
-type speechRepository_local_stub struct {
-	impl                SpeechRepository
-	tracer              trace.Tracer
-	speechToTextMetrics *codegen.MethodMetrics
+	return s.impl.GetRole(ctx, a0, a1)
 }
 
-// Check that speechRepository_local_stub implements the SpeechRepository interface.
-var _ SpeechRepository = (*speechRepository_local_stub)(nil)
-
-func (s speechRepository_local_stub) SpeechToText(ctx context.Context, a0 []byte) (r0 string, err error) {
+func (s aDocRepository_local_stub) GetState(ctx context.Context, a0 string) (r0 DocumentState, err error) {
 	// Update metrics.
-	begin := s.speechToTextMetrics.Begin()
-	defer func() { s.speechToTextMetrics.End(begin, err != nil, 0, 0) }()
+	begin := s.getStateMetrics.Begin()
+	defer func() { s.getStateMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.tracer.Start(ctx, "main.SpeechRepository.SpeechToText", trace.WithSpanKind(trace.SpanKindInternal))
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.GetState", trace.WithSpanKind(trace.SpanKindInternal))
 		defer func() {
 			if err != nil {
 				span.RecordError(err)
@@ -289,407 +441,5369 @@ func (s speechRepository_local_stub) SpeechToText(ctx context.Context, a0 []byte
 		}()
 	}
 
-	return s.impl.SpeechToText(ctx, a0)
+	return s.impl.GetState(ctx, a0)
 }
 
-// Client stub implementations.
+func (s aDocRepository_local_stub) GetVisibleFiles(ctx context.Context, a0 string) (r0 []string, err error) {
+	// Update metrics.
+	begin := s.getVisibleFilesMetrics.Begin()
+	defer func() { s.getVisibleFilesMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.GetVisibleFiles", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
 
-type aDocRepository_client_stub struct {
-	stub                      codegen.Stub
-	getFilesMetrics           *codegen.MethodMetrics
-	readFileMetrics           *codegen.MethodMetrics
-	saveVariantForFileMetrics *codegen.MethodMetrics
+	return s.impl.GetVisibleFiles(ctx, a0)
 }
 
-// Check that aDocRepository_client_stub implements the ADocRepository interface.
-var _ ADocRepository = (*aDocRepository_client_stub)(nil)
-
-func (s aDocRepository_client_stub) GetFiles(ctx context.Context) (r0 []string, err error) {
+func (s aDocRepository_local_stub) ListVersions(ctx context.Context, a0 string) (r0 []DocumentVersion, err error) {
 	// Update metrics.
-	var requestBytes, replyBytes int
-	begin := s.getFilesMetrics.Begin()
-	defer func() { s.getFilesMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
-
+	begin := s.listVersionsMetrics.Begin()
+	defer func() { s.listVersionsMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.GetFiles", trace.WithSpanKind(trace.SpanKindClient))
-	}
-
-	defer func() {
-		// Catch and return any panics detected during encoding/decoding/rpc.
-		if err == nil {
-			err = codegen.CatchPanics(recover())
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.ListVersions", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
 			if err != nil {
-				err = errors.Join(weaver.RemoteCallError, err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
 			}
-		}
-
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
-		span.End()
-
-	}()
-
-	var shardKey uint64
-
-	// Call the remote method.
-	var results []byte
-	results, err = s.stub.Run(ctx, 0, nil, shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+			span.End()
+		}()
 	}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_string_4af10117(dec)
-	err = dec.Error()
-	return
+	return s.impl.ListVersions(ctx, a0)
 }
 
-func (s aDocRepository_client_stub) ReadFile(ctx context.Context, a0 string) (r0 []byte, err error) {
+func (s aDocRepository_local_stub) ReadFile(ctx context.Context, a0 string) (r0 []byte, err error) {
 	// Update metrics.
-	var requestBytes, replyBytes int
 	begin := s.readFileMetrics.Begin()
-	defer func() { s.readFileMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
-
+	defer func() { s.readFileMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.ReadFile", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.ReadFile", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
 	}
 
-	defer func() {
-		// Catch and return any panics detected during encoding/decoding/rpc.
-		if err == nil {
-			err = codegen.CatchPanics(recover())
+	return s.impl.ReadFile(ctx, a0)
+}
+
+func (s aDocRepository_local_stub) ReadFinalPDF(ctx context.Context, a0 string) (r0 []byte, err error) {
+	// Update metrics.
+	begin := s.readFinalPDFMetrics.Begin()
+	defer func() { s.readFinalPDFMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.ReadFinalPDF", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
 			if err != nil {
-				err = errors.Join(weaver.RemoteCallError, err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
 			}
-		}
-
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
-		span.End()
+			span.End()
+		}()
+	}
 
-	}()
+	return s.impl.ReadFinalPDF(ctx, a0)
+}
 
-	// Preallocate a buffer of the right size.
-	size := 0
-	size += (4 + len(a0))
-	enc := codegen.NewEncoder()
-	enc.Reset(size)
-
-	// Encode arguments.
-	enc.String(a0)
-	var shardKey uint64
-
-	// Call the remote method.
-	requestBytes = len(enc.Data())
-	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+func (s aDocRepository_local_stub) ReadVersion(ctx context.Context, a0 string, a1 int) (r0 []byte, err error) {
+	// Update metrics.
+	begin := s.readVersionMetrics.Begin()
+	defer func() { s.readVersionMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.ReadVersion", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
 	}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_byte_87461245(dec)
-	err = dec.Error()
-	return
+	return s.impl.ReadVersion(ctx, a0, a1)
 }
 
-func (s aDocRepository_client_stub) SaveVariantForFile(ctx context.Context, a0 string, a1 []byte) (err error) {
+func (s aDocRepository_local_stub) SaveVariantForFile(ctx context.Context, a0 string, a1 []byte, a2 string, a3 string) (err error) {
 	// Update metrics.
-	var requestBytes, replyBytes int
 	begin := s.saveVariantForFileMetrics.Begin()
-	defer func() { s.saveVariantForFileMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
-
+	defer func() { s.saveVariantForFileMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.SaveVariantForFile", trace.WithSpanKind(trace.SpanKindClient))
-	}
-
-	defer func() {
-		// Catch and return any panics detected during encoding/decoding/rpc.
-		if err == nil {
-			err = codegen.CatchPanics(recover())
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.SaveVariantForFile", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
 			if err != nil {
-				err = errors.Join(weaver.RemoteCallError, err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
 			}
-		}
-
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
-		span.End()
+			span.End()
+		}()
+	}
 
-	}()
+	return s.impl.SaveVariantForFile(ctx, a0, a1, a2, a3)
+}
 
-	// Preallocate a buffer of the right size.
-	size := 0
-	size += (4 + len(a0))
-	size += (4 + (len(a1) * 1))
-	enc := codegen.NewEncoder()
-	enc.Reset(size)
+func (s aDocRepository_local_stub) Share(ctx context.Context, a0 string, a1 string, a2 string) (r0 AccessList, err error) {
+	// Update metrics.
+	begin := s.shareMetrics.Begin()
+	defer func() { s.shareMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.Share", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
 
-	// Encode arguments.
-	enc.String(a0)
-	serviceweaver_enc_slice_byte_87461245(enc, a1)
-	var shardKey uint64
+	return s.impl.Share(ctx, a0, a1, a2)
+}
 
-	// Call the remote method.
-	requestBytes = len(enc.Data())
-	var results []byte
-	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+func (s aDocRepository_local_stub) Undo(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	begin := s.undoMetrics.Begin()
+	defer func() { s.undoMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ADocRepository.Undo", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
 	}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	err = dec.Error()
-	return
+	return s.impl.Undo(ctx, a0)
 }
 
-type chatGPTRepository_client_stub struct {
-	stub                codegen.Stub
-	changeMarkupMetrics *codegen.MethodMetrics
+type authenticator_local_stub struct {
+	impl                Authenticator
+	tracer              trace.Tracer
+	authenticateMetrics *codegen.MethodMetrics
+	issueSessionMetrics *codegen.MethodMetrics
+	oIDCEnabledMetrics  *codegen.MethodMetrics
+	oIDCExchangeMetrics *codegen.MethodMetrics
+	oIDCLoginURLMetrics *codegen.MethodMetrics
 }
 
-// Check that chatGPTRepository_client_stub implements the ChatGPTRepository interface.
-var _ ChatGPTRepository = (*chatGPTRepository_client_stub)(nil)
+// Check that authenticator_local_stub implements the Authenticator interface.
+var _ Authenticator = (*authenticator_local_stub)(nil)
 
-func (s chatGPTRepository_client_stub) ChangeMarkup(ctx context.Context, a0 string, a1 string) (r0 []byte, err error) {
+func (s authenticator_local_stub) Authenticate(ctx context.Context, a0 Credentials) (r0 User, err error) {
 	// Update metrics.
-	var requestBytes, replyBytes int
-	begin := s.changeMarkupMetrics.Begin()
-	defer func() { s.changeMarkupMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
-
+	begin := s.authenticateMetrics.Begin()
+	defer func() { s.authenticateMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.ChatGPTRepository.ChangeMarkup", trace.WithSpanKind(trace.SpanKindClient))
+		ctx, span = s.tracer.Start(ctx, "main.Authenticator.Authenticate", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
 	}
 
-	defer func() {
-		// Catch and return any panics detected during encoding/decoding/rpc.
-		if err == nil {
-			err = codegen.CatchPanics(recover())
+	return s.impl.Authenticate(ctx, a0)
+}
+
+func (s authenticator_local_stub) IssueSession(ctx context.Context, a0 User) (r0 string, err error) {
+	// Update metrics.
+	begin := s.issueSessionMetrics.Begin()
+	defer func() { s.issueSessionMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.Authenticator.IssueSession", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
 			if err != nil {
-				err = errors.Join(weaver.RemoteCallError, err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
 			}
-		}
-
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
-		span.End()
+			span.End()
+		}()
+	}
 
-	}()
+	return s.impl.IssueSession(ctx, a0)
+}
 
-	// Preallocate a buffer of the right size.
-	size := 0
-	size += (4 + len(a0))
-	size += (4 + len(a1))
-	enc := codegen.NewEncoder()
-	enc.Reset(size)
+func (s authenticator_local_stub) OIDCEnabled(ctx context.Context) (r0 bool, err error) {
+	// Update metrics.
+	begin := s.oIDCEnabledMetrics.Begin()
+	defer func() { s.oIDCEnabledMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.Authenticator.OIDCEnabled", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
 
-	// Encode arguments.
-	enc.String(a0)
-	enc.String(a1)
-	var shardKey uint64
+	return s.impl.OIDCEnabled(ctx)
+}
 
-	// Call the remote method.
-	requestBytes = len(enc.Data())
-	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+func (s authenticator_local_stub) OIDCExchange(ctx context.Context, a0 string, a1 string) (r0 User, err error) {
+	// Update metrics.
+	begin := s.oIDCExchangeMetrics.Begin()
+	defer func() { s.oIDCExchangeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.Authenticator.OIDCExchange", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
 	}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_byte_87461245(dec)
-	err = dec.Error()
-	return
+	return s.impl.OIDCExchange(ctx, a0, a1)
 }
 
-type main_client_stub struct {
-	stub codegen.Stub
-}
+func (s authenticator_local_stub) OIDCLoginURL(ctx context.Context, a0 string, a1 string) (r0 string, err error) {
+	// Update metrics.
+	begin := s.oIDCLoginURLMetrics.Begin()
+	defer func() { s.oIDCLoginURLMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.Authenticator.OIDCLoginURL", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
 
-// Check that main_client_stub implements the weaver.Main interface.
-var _ weaver.Main = (*main_client_stub)(nil)
+	return s.impl.OIDCLoginURL(ctx, a0, a1)
+}
 
-type pDFGenerator_client_stub struct {
-	stub               codegen.Stub
-	generatePDFMetrics *codegen.MethodMetrics
+type chatGPTRepository_local_stub struct {
+	impl                 ChatGPTRepository
+	tracer               trace.Tracer
+	changeMarkupMetrics  *codegen.MethodMetrics
+	estimateUsageMetrics *codegen.MethodMetrics
 }
 
-// Check that pDFGenerator_client_stub implements the PDFGenerator interface.
-var _ PDFGenerator = (*pDFGenerator_client_stub)(nil)
+// Check that chatGPTRepository_local_stub implements the ChatGPTRepository interface.
+var _ ChatGPTRepository = (*chatGPTRepository_local_stub)(nil)
 
-func (s pDFGenerator_client_stub) GeneratePDF(ctx context.Context, a0 []byte) (r0 []byte, err error) {
+func (s chatGPTRepository_local_stub) ChangeMarkup(ctx context.Context, a0 string, a1 string, a2 bool) (r0 []byte, r1 TokenUsage, err error) {
 	// Update metrics.
-	var requestBytes, replyBytes int
-	begin := s.generatePDFMetrics.Begin()
-	defer func() { s.generatePDFMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
-
+	begin := s.changeMarkupMetrics.Begin()
+	defer func() { s.changeMarkupMetrics.End(begin, err != nil, 0, 0) }()
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.PDFGenerator.GeneratePDF", trace.WithSpanKind(trace.SpanKindClient))
-	}
-
-	defer func() {
-		// Catch and return any panics detected during encoding/decoding/rpc.
-		if err == nil {
-			err = codegen.CatchPanics(recover())
+		ctx, span = s.tracer.Start(ctx, "main.ChatGPTRepository.ChangeMarkup", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
 			if err != nil {
-				err = errors.Join(weaver.RemoteCallError, err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
 			}
-		}
-
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
-		span.End()
+			span.End()
+		}()
+	}
 
-	}()
+	return s.impl.ChangeMarkup(ctx, a0, a1, a2)
+}
 
-	// Preallocate a buffer of the right size.
-	size := 0
+func (s chatGPTRepository_local_stub) EstimateUsage(ctx context.Context, a0 string, a1 string) (r0 TokenUsage, err error) {
+	// Update metrics.
+	begin := s.estimateUsageMetrics.Begin()
+	defer func() { s.estimateUsageMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ChatGPTRepository.EstimateUsage", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.EstimateUsage(ctx, a0, a1)
+}
+
+type invoiceService_local_stub struct {
+	impl             InvoiceService
+	tracer           trace.Tracer
+	checkMetrics     *codegen.MethodMetrics
+	reconcileMetrics *codegen.MethodMetrics
+}
+
+// Check that invoiceService_local_stub implements the InvoiceService interface.
+var _ InvoiceService = (*invoiceService_local_stub)(nil)
+
+func (s invoiceService_local_stub) Check(ctx context.Context, a0 []byte) (r0 InvoiceReport, err error) {
+	// Update metrics.
+	begin := s.checkMetrics.Begin()
+	defer func() { s.checkMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.InvoiceService.Check", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Check(ctx, a0)
+}
+
+func (s invoiceService_local_stub) Reconcile(ctx context.Context, a0 []byte) (r0 []byte, r1 InvoiceReport, err error) {
+	// Update metrics.
+	begin := s.reconcileMetrics.Begin()
+	defer func() { s.reconcileMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.InvoiceService.Reconcile", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Reconcile(ctx, a0)
+}
+
+type mailMerger_local_stub struct {
+	impl                 MailMerger
+	tracer               trace.Tracer
+	mergeToFolderMetrics *codegen.MethodMetrics
+	mergeToZipMetrics    *codegen.MethodMetrics
+}
+
+// Check that mailMerger_local_stub implements the MailMerger interface.
+var _ MailMerger = (*mailMerger_local_stub)(nil)
+
+func (s mailMerger_local_stub) MergeToFolder(ctx context.Context, a0 string, a1 []MergeRow) (r0 []string, err error) {
+	// Update metrics.
+	begin := s.mergeToFolderMetrics.Begin()
+	defer func() { s.mergeToFolderMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.MailMerger.MergeToFolder", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.MergeToFolder(ctx, a0, a1)
+}
+
+func (s mailMerger_local_stub) MergeToZip(ctx context.Context, a0 string, a1 []MergeRow) (r0 []byte, err error) {
+	// Update metrics.
+	begin := s.mergeToZipMetrics.Begin()
+	defer func() { s.mergeToZipMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.MailMerger.MergeToZip", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.MergeToZip(ctx, a0, a1)
+}
+
+type main_local_stub struct {
+	impl   weaver.Main
+	tracer trace.Tracer
+}
+
+// Check that main_local_stub implements the weaver.Main interface.
+var _ weaver.Main = (*main_local_stub)(nil)
+
+type pDFGenerator_local_stub struct {
+	impl               PDFGenerator
+	tracer             trace.Tracer
+	generatePDFMetrics *codegen.MethodMetrics
+	guardMarkupMetrics *codegen.MethodMetrics
+}
+
+// Check that pDFGenerator_local_stub implements the PDFGenerator interface.
+var _ PDFGenerator = (*pDFGenerator_local_stub)(nil)
+
+func (s pDFGenerator_local_stub) GeneratePDF(ctx context.Context, a0 []byte) (r0 []byte, err error) {
+	// Update metrics.
+	begin := s.generatePDFMetrics.Begin()
+	defer func() { s.generatePDFMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.PDFGenerator.GeneratePDF", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.GeneratePDF(ctx, a0)
+}
+
+func (s pDFGenerator_local_stub) GuardMarkup(ctx context.Context, a0 []byte) (r0 []byte, r1 []BlockedDirective, err error) {
+	// Update metrics.
+	begin := s.guardMarkupMetrics.Begin()
+	defer func() { s.guardMarkupMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.PDFGenerator.GuardMarkup", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.GuardMarkup(ctx, a0)
+}
+
+type promptTemplates_local_stub struct {
+	impl          PromptTemplates
+	tracer        trace.Tracer
+	deleteMetrics *codegen.MethodMetrics
+	getMetrics    *codegen.MethodMetrics
+	listMetrics   *codegen.MethodMetrics
+	saveMetrics   *codegen.MethodMetrics
+}
+
+// Check that promptTemplates_local_stub implements the PromptTemplates interface.
+var _ PromptTemplates = (*promptTemplates_local_stub)(nil)
+
+func (s promptTemplates_local_stub) Delete(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	begin := s.deleteMetrics.Begin()
+	defer func() { s.deleteMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.PromptTemplates.Delete", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Delete(ctx, a0)
+}
+
+func (s promptTemplates_local_stub) Get(ctx context.Context, a0 string) (r0 PromptTemplate, err error) {
+	// Update metrics.
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.PromptTemplates.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Get(ctx, a0)
+}
+
+func (s promptTemplates_local_stub) List(ctx context.Context) (r0 []PromptTemplate, err error) {
+	// Update metrics.
+	begin := s.listMetrics.Begin()
+	defer func() { s.listMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.PromptTemplates.List", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.List(ctx)
+}
+
+func (s promptTemplates_local_stub) Save(ctx context.Context, a0 PromptTemplate) (r0 PromptTemplate, err error) {
+	// Update metrics.
+	begin := s.saveMetrics.Begin()
+	defer func() { s.saveMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.PromptTemplates.Save", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Save(ctx, a0)
+}
+
+type sequenceService_local_stub struct {
+	impl                    SequenceService
+	tracer                  trace.Tracer
+	commitMetrics           *codegen.MethodMetrics
+	fillPlaceholdersMetrics *codegen.MethodMetrics
+	nextMetrics             *codegen.MethodMetrics
+	releaseMetrics          *codegen.MethodMetrics
+}
+
+// Check that sequenceService_local_stub implements the SequenceService interface.
+var _ SequenceService = (*sequenceService_local_stub)(nil)
+
+func (s sequenceService_local_stub) Commit(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	begin := s.commitMetrics.Begin()
+	defer func() { s.commitMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.SequenceService.Commit", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Commit(ctx, a0)
+}
+
+func (s sequenceService_local_stub) FillPlaceholders(ctx context.Context, a0 string, a1 []byte, a2 bool) (r0 []byte, r1 string, err error) {
+	// Update metrics.
+	begin := s.fillPlaceholdersMetrics.Begin()
+	defer func() { s.fillPlaceholdersMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.SequenceService.FillPlaceholders", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.FillPlaceholders(ctx, a0, a1, a2)
+}
+
+func (s sequenceService_local_stub) Next(ctx context.Context, a0 string, a1 string, a2 string) (r0 string, err error) {
+	// Update metrics.
+	begin := s.nextMetrics.Begin()
+	defer func() { s.nextMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.SequenceService.Next", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Next(ctx, a0, a1, a2)
+}
+
+func (s sequenceService_local_stub) Release(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	begin := s.releaseMetrics.Begin()
+	defer func() { s.releaseMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.SequenceService.Release", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Release(ctx, a0)
+}
+
+type shareLinks_local_stub struct {
+	impl           ShareLinks
+	tracer         trace.Tracer
+	createMetrics  *codegen.MethodMetrics
+	getMetrics     *codegen.MethodMetrics
+	listMetrics    *codegen.MethodMetrics
+	resolveMetrics *codegen.MethodMetrics
+	revokeMetrics  *codegen.MethodMetrics
+}
+
+// Check that shareLinks_local_stub implements the ShareLinks interface.
+var _ ShareLinks = (*shareLinks_local_stub)(nil)
+
+func (s shareLinks_local_stub) Create(ctx context.Context, a0 ShareLink, a1 []byte) (r0 ShareLink, r1 string, err error) {
+	// Update metrics.
+	begin := s.createMetrics.Begin()
+	defer func() { s.createMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ShareLinks.Create", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Create(ctx, a0, a1)
+}
+
+func (s shareLinks_local_stub) Get(ctx context.Context, a0 string) (r0 ShareLink, err error) {
+	// Update metrics.
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ShareLinks.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Get(ctx, a0)
+}
+
+func (s shareLinks_local_stub) List(ctx context.Context) (r0 []ShareLink, err error) {
+	// Update metrics.
+	begin := s.listMetrics.Begin()
+	defer func() { s.listMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ShareLinks.List", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.List(ctx)
+}
+
+func (s shareLinks_local_stub) Resolve(ctx context.Context, a0 string) (r0 ShareLink, r1 []byte, err error) {
+	// Update metrics.
+	begin := s.resolveMetrics.Begin()
+	defer func() { s.resolveMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ShareLinks.Resolve", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Resolve(ctx, a0)
+}
+
+func (s shareLinks_local_stub) Revoke(ctx context.Context, a0 string) (r0 ShareLink, err error) {
+	// Update metrics.
+	begin := s.revokeMetrics.Begin()
+	defer func() { s.revokeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.ShareLinks.Revoke", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Revoke(ctx, a0)
+}
+
+type speechRepository_local_stub struct {
+	impl                SpeechRepository
+	tracer              trace.Tracer
+	speechToTextMetrics *codegen.MethodMetrics
+}
+
+// Check that speechRepository_local_stub implements the SpeechRepository interface.
+var _ SpeechRepository = (*speechRepository_local_stub)(nil)
+
+func (s speechRepository_local_stub) SpeechToText(ctx context.Context, a0 []byte, a1 TranscriptionOptions) (r0 string, err error) {
+	// Update metrics.
+	begin := s.speechToTextMetrics.Begin()
+	defer func() { s.speechToTextMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.SpeechRepository.SpeechToText", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.SpeechToText(ctx, a0, a1)
+}
+
+type textToSpeech_local_stub struct {
+	impl              TextToSpeech
+	tracer            trace.Tracer
+	synthesizeMetrics *codegen.MethodMetrics
+}
+
+// Check that textToSpeech_local_stub implements the TextToSpeech interface.
+var _ TextToSpeech = (*textToSpeech_local_stub)(nil)
+
+func (s textToSpeech_local_stub) Synthesize(ctx context.Context, a0 string, a1 string) (r0 SpeechAudio, err error) {
+	// Update metrics.
+	begin := s.synthesizeMetrics.Begin()
+	defer func() { s.synthesizeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.TextToSpeech.Synthesize", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Synthesize(ctx, a0, a1)
+}
+
+type usageLedger_local_stub struct {
+	impl               UsageLedger
+	tracer             trace.Tracer
+	checkBudgetMetrics *codegen.MethodMetrics
+	recordMetrics      *codegen.MethodMetrics
+	summarizeMetrics   *codegen.MethodMetrics
+}
+
+// Check that usageLedger_local_stub implements the UsageLedger interface.
+var _ UsageLedger = (*usageLedger_local_stub)(nil)
+
+func (s usageLedger_local_stub) CheckBudget(ctx context.Context, a0 string, a1 string, a2 TokenUsage) (err error) {
+	// Update metrics.
+	begin := s.checkBudgetMetrics.Begin()
+	defer func() { s.checkBudgetMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.UsageLedger.CheckBudget", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.CheckBudget(ctx, a0, a1, a2)
+}
+
+func (s usageLedger_local_stub) Record(ctx context.Context, a0 UsageRecord) (r0 UsageRecord, err error) {
+	// Update metrics.
+	begin := s.recordMetrics.Begin()
+	defer func() { s.recordMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.UsageLedger.Record", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Record(ctx, a0)
+}
+
+func (s usageLedger_local_stub) Summarize(ctx context.Context, a0 UsageQuery) (r0 []UsageAggregate, err error) {
+	// Update metrics.
+	begin := s.summarizeMetrics.Begin()
+	defer func() { s.summarizeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.UsageLedger.Summarize", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Summarize(ctx, a0)
+}
+
+type voiceArchive_local_stub struct {
+	impl             VoiceArchive
+	tracer           trace.Tracer
+	listMetrics      *codegen.MethodMetrics
+	readAudioMetrics *codegen.MethodMetrics
+	storeMetrics     *codegen.MethodMetrics
+}
+
+// Check that voiceArchive_local_stub implements the VoiceArchive interface.
+var _ VoiceArchive = (*voiceArchive_local_stub)(nil)
+
+func (s voiceArchive_local_stub) List(ctx context.Context, a0 string) (r0 []VoicePrompt, err error) {
+	// Update metrics.
+	begin := s.listMetrics.Begin()
+	defer func() { s.listMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.VoiceArchive.List", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.List(ctx, a0)
+}
+
+func (s voiceArchive_local_stub) ReadAudio(ctx context.Context, a0 string) (r0 VoicePrompt, r1 []byte, err error) {
+	// Update metrics.
+	begin := s.readAudioMetrics.Begin()
+	defer func() { s.readAudioMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.VoiceArchive.ReadAudio", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.ReadAudio(ctx, a0)
+}
+
+func (s voiceArchive_local_stub) Store(ctx context.Context, a0 VoicePrompt, a1 []byte) (r0 string, err error) {
+	// Update metrics.
+	begin := s.storeMetrics.Begin()
+	defer func() { s.storeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "main.VoiceArchive.Store", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Store(ctx, a0, a1)
+}
+
+// Client stub implementations.
+
+type aDocRepository_client_stub struct {
+	stub                      codegen.Stub
+	archiveMetrics            *codegen.MethodMetrics
+	authorizeMetrics          *codegen.MethodMetrics
+	finalizeMetrics           *codegen.MethodMetrics
+	getAccessMetrics          *codegen.MethodMetrics
+	getFilesMetrics           *codegen.MethodMetrics
+	getRoleMetrics            *codegen.MethodMetrics
+	getStateMetrics           *codegen.MethodMetrics
+	getVisibleFilesMetrics    *codegen.MethodMetrics
+	listVersionsMetrics       *codegen.MethodMetrics
+	readFileMetrics           *codegen.MethodMetrics
+	readFinalPDFMetrics       *codegen.MethodMetrics
+	readVersionMetrics        *codegen.MethodMetrics
+	saveVariantForFileMetrics *codegen.MethodMetrics
+	shareMetrics              *codegen.MethodMetrics
+	undoMetrics               *codegen.MethodMetrics
+}
+
+// Check that aDocRepository_client_stub implements the ADocRepository interface.
+var _ ADocRepository = (*aDocRepository_client_stub)(nil)
+
+func (s aDocRepository_client_stub) Archive(ctx context.Context, a0 string) (r0 DocumentState, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.archiveMetrics.Begin()
+	defer func() { s.archiveMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.Archive", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) Authorize(ctx context.Context, a0 string, a1 string, a2 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.authorizeMetrics.Begin()
+	defer func() { s.authorizeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.Authorize", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	size += (4 + len(a2))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	enc.String(a2)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) Finalize(ctx context.Context, a0 string, a1 []byte, a2 []byte) (r0 DocumentState, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.finalizeMetrics.Begin()
+	defer func() { s.finalizeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.Finalize", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + (len(a1) * 1))
+	size += (4 + (len(a2) * 1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	serviceweaver_enc_slice_byte_87461245(enc, a1)
+	serviceweaver_enc_slice_byte_87461245(enc, a2)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) GetAccess(ctx context.Context, a0 string) (r0 AccessList, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getAccessMetrics.Begin()
+	defer func() { s.getAccessMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.GetAccess", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) GetFiles(ctx context.Context) (r0 []string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getFilesMetrics.Begin()
+	defer func() { s.getFilesMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.GetFiles", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 4, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_string_4af10117(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) GetRole(ctx context.Context, a0 string, a1 string) (r0 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getRoleMetrics.Begin()
+	defer func() { s.getRoleMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.GetRole", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 5, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = dec.String()
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) GetState(ctx context.Context, a0 string) (r0 DocumentState, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getStateMetrics.Begin()
+	defer func() { s.getStateMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.GetState", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 6, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) GetVisibleFiles(ctx context.Context, a0 string) (r0 []string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getVisibleFilesMetrics.Begin()
+	defer func() { s.getVisibleFilesMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.GetVisibleFiles", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 7, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_string_4af10117(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) ListVersions(ctx context.Context, a0 string) (r0 []DocumentVersion, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.listVersionsMetrics.Begin()
+	defer func() { s.listVersionsMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.ListVersions", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 8, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_DocumentVersion_b96aaf13(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) ReadFile(ctx context.Context, a0 string) (r0 []byte, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.readFileMetrics.Begin()
+	defer func() { s.readFileMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.ReadFile", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 9, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) ReadFinalPDF(ctx context.Context, a0 string) (r0 []byte, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.readFinalPDFMetrics.Begin()
+	defer func() { s.readFinalPDFMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.ReadFinalPDF", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 10, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) ReadVersion(ctx context.Context, a0 string, a1 int) (r0 []byte, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.readVersionMetrics.Begin()
+	defer func() { s.readVersionMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.ReadVersion", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.Int(a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 11, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) SaveVariantForFile(ctx context.Context, a0 string, a1 []byte, a2 string, a3 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.saveVariantForFileMetrics.Begin()
+	defer func() { s.saveVariantForFileMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.SaveVariantForFile", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + (len(a1) * 1))
+	size += (4 + len(a2))
+	size += (4 + len(a3))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	serviceweaver_enc_slice_byte_87461245(enc, a1)
+	enc.String(a2)
+	enc.String(a3)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 12, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) Share(ctx context.Context, a0 string, a1 string, a2 string) (r0 AccessList, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.shareMetrics.Begin()
+	defer func() { s.shareMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.Share", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	size += (4 + len(a2))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	enc.String(a2)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 13, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s aDocRepository_client_stub) Undo(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.undoMetrics.Begin()
+	defer func() { s.undoMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ADocRepository.Undo", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 14, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+type authenticator_client_stub struct {
+	stub                codegen.Stub
+	authenticateMetrics *codegen.MethodMetrics
+	issueSessionMetrics *codegen.MethodMetrics
+	oIDCEnabledMetrics  *codegen.MethodMetrics
+	oIDCExchangeMetrics *codegen.MethodMetrics
+	oIDCLoginURLMetrics *codegen.MethodMetrics
+}
+
+// Check that authenticator_client_stub implements the Authenticator interface.
+var _ Authenticator = (*authenticator_client_stub)(nil)
+
+func (s authenticator_client_stub) Authenticate(ctx context.Context, a0 Credentials) (r0 User, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.authenticateMetrics.Begin()
+	defer func() { s.authenticateMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.Authenticator.Authenticate", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += serviceweaver_size_Credentials_7245487d(&a0)
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	(a0).WeaverMarshal(enc)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s authenticator_client_stub) IssueSession(ctx context.Context, a0 User) (r0 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.issueSessionMetrics.Begin()
+	defer func() { s.issueSessionMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.Authenticator.IssueSession", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += serviceweaver_size_User_add32a16(&a0)
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	(a0).WeaverMarshal(enc)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = dec.String()
+	err = dec.Error()
+	return
+}
+
+func (s authenticator_client_stub) OIDCEnabled(ctx context.Context) (r0 bool, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.oIDCEnabledMetrics.Begin()
+	defer func() { s.oIDCEnabledMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.Authenticator.OIDCEnabled", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = dec.Bool()
+	err = dec.Error()
+	return
+}
+
+func (s authenticator_client_stub) OIDCExchange(ctx context.Context, a0 string, a1 string) (r0 User, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.oIDCExchangeMetrics.Begin()
+	defer func() { s.oIDCExchangeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.Authenticator.OIDCExchange", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s authenticator_client_stub) OIDCLoginURL(ctx context.Context, a0 string, a1 string) (r0 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.oIDCLoginURLMetrics.Begin()
+	defer func() { s.oIDCLoginURLMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.Authenticator.OIDCLoginURL", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 4, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = dec.String()
+	err = dec.Error()
+	return
+}
+
+type chatGPTRepository_client_stub struct {
+	stub                 codegen.Stub
+	changeMarkupMetrics  *codegen.MethodMetrics
+	estimateUsageMetrics *codegen.MethodMetrics
+}
+
+// Check that chatGPTRepository_client_stub implements the ChatGPTRepository interface.
+var _ ChatGPTRepository = (*chatGPTRepository_client_stub)(nil)
+
+func (s chatGPTRepository_client_stub) ChangeMarkup(ctx context.Context, a0 string, a1 string, a2 bool) (r0 []byte, r1 TokenUsage, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.changeMarkupMetrics.Begin()
+	defer func() { s.changeMarkupMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ChatGPTRepository.ChangeMarkup", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	size += 1
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	enc.Bool(a2)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	(&r1).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s chatGPTRepository_client_stub) EstimateUsage(ctx context.Context, a0 string, a1 string) (r0 TokenUsage, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.estimateUsageMetrics.Begin()
+	defer func() { s.estimateUsageMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ChatGPTRepository.EstimateUsage", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+type invoiceService_client_stub struct {
+	stub             codegen.Stub
+	checkMetrics     *codegen.MethodMetrics
+	reconcileMetrics *codegen.MethodMetrics
+}
+
+// Check that invoiceService_client_stub implements the InvoiceService interface.
+var _ InvoiceService = (*invoiceService_client_stub)(nil)
+
+func (s invoiceService_client_stub) Check(ctx context.Context, a0 []byte) (r0 InvoiceReport, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.checkMetrics.Begin()
+	defer func() { s.checkMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.InvoiceService.Check", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + (len(a0) * 1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_byte_87461245(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s invoiceService_client_stub) Reconcile(ctx context.Context, a0 []byte) (r0 []byte, r1 InvoiceReport, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.reconcileMetrics.Begin()
+	defer func() { s.reconcileMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.InvoiceService.Reconcile", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + (len(a0) * 1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_byte_87461245(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	(&r1).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+type mailMerger_client_stub struct {
+	stub                 codegen.Stub
+	mergeToFolderMetrics *codegen.MethodMetrics
+	mergeToZipMetrics    *codegen.MethodMetrics
+}
+
+// Check that mailMerger_client_stub implements the MailMerger interface.
+var _ MailMerger = (*mailMerger_client_stub)(nil)
+
+func (s mailMerger_client_stub) MergeToFolder(ctx context.Context, a0 string, a1 []MergeRow) (r0 []string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.mergeToFolderMetrics.Begin()
+	defer func() { s.mergeToFolderMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.MailMerger.MergeToFolder", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	enc.String(a0)
+	serviceweaver_enc_slice_MergeRow_61df193f(enc, a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_string_4af10117(dec)
+	err = dec.Error()
+	return
+}
+
+func (s mailMerger_client_stub) MergeToZip(ctx context.Context, a0 string, a1 []MergeRow) (r0 []byte, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.mergeToZipMetrics.Begin()
+	defer func() { s.mergeToZipMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.MailMerger.MergeToZip", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	enc.String(a0)
+	serviceweaver_enc_slice_MergeRow_61df193f(enc, a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	err = dec.Error()
+	return
+}
+
+type main_client_stub struct {
+	stub codegen.Stub
+}
+
+// Check that main_client_stub implements the weaver.Main interface.
+var _ weaver.Main = (*main_client_stub)(nil)
+
+type pDFGenerator_client_stub struct {
+	stub               codegen.Stub
+	generatePDFMetrics *codegen.MethodMetrics
+	guardMarkupMetrics *codegen.MethodMetrics
+}
+
+// Check that pDFGenerator_client_stub implements the PDFGenerator interface.
+var _ PDFGenerator = (*pDFGenerator_client_stub)(nil)
+
+func (s pDFGenerator_client_stub) GeneratePDF(ctx context.Context, a0 []byte) (r0 []byte, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.generatePDFMetrics.Begin()
+	defer func() { s.generatePDFMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.PDFGenerator.GeneratePDF", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + (len(a0) * 1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_byte_87461245(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	err = dec.Error()
+	return
+}
+
+func (s pDFGenerator_client_stub) GuardMarkup(ctx context.Context, a0 []byte) (r0 []byte, r1 []BlockedDirective, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.guardMarkupMetrics.Begin()
+	defer func() { s.guardMarkupMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.PDFGenerator.GuardMarkup", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + (len(a0) * 1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_byte_87461245(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	r1 = serviceweaver_dec_slice_BlockedDirective_f4d6bad9(dec)
+	err = dec.Error()
+	return
+}
+
+type promptTemplates_client_stub struct {
+	stub          codegen.Stub
+	deleteMetrics *codegen.MethodMetrics
+	getMetrics    *codegen.MethodMetrics
+	listMetrics   *codegen.MethodMetrics
+	saveMetrics   *codegen.MethodMetrics
+}
+
+// Check that promptTemplates_client_stub implements the PromptTemplates interface.
+var _ PromptTemplates = (*promptTemplates_client_stub)(nil)
+
+func (s promptTemplates_client_stub) Delete(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.deleteMetrics.Begin()
+	defer func() { s.deleteMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.PromptTemplates.Delete", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s promptTemplates_client_stub) Get(ctx context.Context, a0 string) (r0 PromptTemplate, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.PromptTemplates.Get", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s promptTemplates_client_stub) List(ctx context.Context) (r0 []PromptTemplate, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.listMetrics.Begin()
+	defer func() { s.listMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.PromptTemplates.List", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_PromptTemplate_17961a57(dec)
+	err = dec.Error()
+	return
+}
+
+func (s promptTemplates_client_stub) Save(ctx context.Context, a0 PromptTemplate) (r0 PromptTemplate, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.saveMetrics.Begin()
+	defer func() { s.saveMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.PromptTemplates.Save", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	(a0).WeaverMarshal(enc)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+type sequenceService_client_stub struct {
+	stub                    codegen.Stub
+	commitMetrics           *codegen.MethodMetrics
+	fillPlaceholdersMetrics *codegen.MethodMetrics
+	nextMetrics             *codegen.MethodMetrics
+	releaseMetrics          *codegen.MethodMetrics
+}
+
+// Check that sequenceService_client_stub implements the SequenceService interface.
+var _ SequenceService = (*sequenceService_client_stub)(nil)
+
+func (s sequenceService_client_stub) Commit(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.commitMetrics.Begin()
+	defer func() { s.commitMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.SequenceService.Commit", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s sequenceService_client_stub) FillPlaceholders(ctx context.Context, a0 string, a1 []byte, a2 bool) (r0 []byte, r1 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.fillPlaceholdersMetrics.Begin()
+	defer func() { s.fillPlaceholdersMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.SequenceService.FillPlaceholders", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + (len(a1) * 1))
+	size += 1
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	serviceweaver_enc_slice_byte_87461245(enc, a1)
+	enc.Bool(a2)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_byte_87461245(dec)
+	r1 = dec.String()
+	err = dec.Error()
+	return
+}
+
+func (s sequenceService_client_stub) Next(ctx context.Context, a0 string, a1 string, a2 string) (r0 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.nextMetrics.Begin()
+	defer func() { s.nextMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.SequenceService.Next", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	size += (4 + len(a2))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	enc.String(a2)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = dec.String()
+	err = dec.Error()
+	return
+}
+
+func (s sequenceService_client_stub) Release(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.releaseMetrics.Begin()
+	defer func() { s.releaseMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.SequenceService.Release", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+type shareLinks_client_stub struct {
+	stub           codegen.Stub
+	createMetrics  *codegen.MethodMetrics
+	getMetrics     *codegen.MethodMetrics
+	listMetrics    *codegen.MethodMetrics
+	resolveMetrics *codegen.MethodMetrics
+	revokeMetrics  *codegen.MethodMetrics
+}
+
+// Check that shareLinks_client_stub implements the ShareLinks interface.
+var _ ShareLinks = (*shareLinks_client_stub)(nil)
+
+func (s shareLinks_client_stub) Create(ctx context.Context, a0 ShareLink, a1 []byte) (r0 ShareLink, r1 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.createMetrics.Begin()
+	defer func() { s.createMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ShareLinks.Create", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	(a0).WeaverMarshal(enc)
+	serviceweaver_enc_slice_byte_87461245(enc, a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	r1 = dec.String()
+	err = dec.Error()
+	return
+}
+
+func (s shareLinks_client_stub) Get(ctx context.Context, a0 string) (r0 ShareLink, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ShareLinks.Get", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s shareLinks_client_stub) List(ctx context.Context) (r0 []ShareLink, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.listMetrics.Begin()
+	defer func() { s.listMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ShareLinks.List", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_ShareLink_d1f3dde3(dec)
+	err = dec.Error()
+	return
+}
+
+func (s shareLinks_client_stub) Resolve(ctx context.Context, a0 string) (r0 ShareLink, r1 []byte, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.resolveMetrics.Begin()
+	defer func() { s.resolveMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ShareLinks.Resolve", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	r1 = serviceweaver_dec_slice_byte_87461245(dec)
+	err = dec.Error()
+	return
+}
+
+func (s shareLinks_client_stub) Revoke(ctx context.Context, a0 string) (r0 ShareLink, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.revokeMetrics.Begin()
+	defer func() { s.revokeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.ShareLinks.Revoke", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 4, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+type speechRepository_client_stub struct {
+	stub                codegen.Stub
+	speechToTextMetrics *codegen.MethodMetrics
+}
+
+// Check that speechRepository_client_stub implements the SpeechRepository interface.
+var _ SpeechRepository = (*speechRepository_client_stub)(nil)
+
+func (s speechRepository_client_stub) SpeechToText(ctx context.Context, a0 []byte, a1 TranscriptionOptions) (r0 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.speechToTextMetrics.Begin()
+	defer func() { s.speechToTextMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.SpeechRepository.SpeechToText", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
 	size += (4 + (len(a0) * 1))
+	size += serviceweaver_size_TranscriptionOptions_203fede0(&a1)
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_byte_87461245(enc, a0)
+	(a1).WeaverMarshal(enc)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = dec.String()
+	err = dec.Error()
+	return
+}
+
+type textToSpeech_client_stub struct {
+	stub              codegen.Stub
+	synthesizeMetrics *codegen.MethodMetrics
+}
+
+// Check that textToSpeech_client_stub implements the TextToSpeech interface.
+var _ TextToSpeech = (*textToSpeech_client_stub)(nil)
+
+func (s textToSpeech_client_stub) Synthesize(ctx context.Context, a0 string, a1 string) (r0 SpeechAudio, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.synthesizeMetrics.Begin()
+	defer func() { s.synthesizeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.TextToSpeech.Synthesize", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+type usageLedger_client_stub struct {
+	stub               codegen.Stub
+	checkBudgetMetrics *codegen.MethodMetrics
+	recordMetrics      *codegen.MethodMetrics
+	summarizeMetrics   *codegen.MethodMetrics
+}
+
+// Check that usageLedger_client_stub implements the UsageLedger interface.
+var _ UsageLedger = (*usageLedger_client_stub)(nil)
+
+func (s usageLedger_client_stub) CheckBudget(ctx context.Context, a0 string, a1 string, a2 TokenUsage) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.checkBudgetMetrics.Begin()
+	defer func() { s.checkBudgetMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.UsageLedger.CheckBudget", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	size += serviceweaver_size_TokenUsage_fbc88ffd(&a2)
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+	(a2).WeaverMarshal(enc)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s usageLedger_client_stub) Record(ctx context.Context, a0 UsageRecord) (r0 UsageRecord, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.recordMetrics.Begin()
+	defer func() { s.recordMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.UsageLedger.Record", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	(a0).WeaverMarshal(enc)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s usageLedger_client_stub) Summarize(ctx context.Context, a0 UsageQuery) (r0 []UsageAggregate, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.summarizeMetrics.Begin()
+	defer func() { s.summarizeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.UsageLedger.Summarize", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	(a0).WeaverMarshal(enc)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_UsageAggregate_ae309bd1(dec)
+	err = dec.Error()
+	return
+}
+
+type voiceArchive_client_stub struct {
+	stub             codegen.Stub
+	listMetrics      *codegen.MethodMetrics
+	readAudioMetrics *codegen.MethodMetrics
+	storeMetrics     *codegen.MethodMetrics
+}
+
+// Check that voiceArchive_client_stub implements the VoiceArchive interface.
+var _ VoiceArchive = (*voiceArchive_client_stub)(nil)
+
+func (s voiceArchive_client_stub) List(ctx context.Context, a0 string) (r0 []VoicePrompt, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.listMetrics.Begin()
+	defer func() { s.listMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.VoiceArchive.List", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_VoicePrompt_012db793(dec)
+	err = dec.Error()
+	return
+}
+
+func (s voiceArchive_client_stub) ReadAudio(ctx context.Context, a0 string) (r0 VoicePrompt, r1 []byte, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.readAudioMetrics.Begin()
+	defer func() { s.readAudioMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.VoiceArchive.ReadAudio", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	r1 = serviceweaver_dec_slice_byte_87461245(dec)
+	err = dec.Error()
+	return
+}
+
+func (s voiceArchive_client_stub) Store(ctx context.Context, a0 VoicePrompt, a1 []byte) (r0 string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.storeMetrics.Begin()
+	defer func() { s.storeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "main.VoiceArchive.Store", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	(a0).WeaverMarshal(enc)
+	serviceweaver_enc_slice_byte_87461245(enc, a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = dec.String()
+	err = dec.Error()
+	return
+}
+
+// Server stub implementations.
+
+type aDocRepository_server_stub struct {
+	impl    ADocRepository
+	addLoad func(key uint64, load float64)
+}
+
+// Check that aDocRepository_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*aDocRepository_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s aDocRepository_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Archive":
+		return s.archive
+	case "Authorize":
+		return s.authorize
+	case "Finalize":
+		return s.finalize
+	case "GetAccess":
+		return s.getAccess
+	case "GetFiles":
+		return s.getFiles
+	case "GetRole":
+		return s.getRole
+	case "GetState":
+		return s.getState
+	case "GetVisibleFiles":
+		return s.getVisibleFiles
+	case "ListVersions":
+		return s.listVersions
+	case "ReadFile":
+		return s.readFile
+	case "ReadFinalPDF":
+		return s.readFinalPDF
+	case "ReadVersion":
+		return s.readVersion
+	case "SaveVariantForFile":
+		return s.saveVariantForFile
+	case "Share":
+		return s.share
+	case "Undo":
+		return s.undo
+	default:
+		return nil
+	}
+}
+
+func (s aDocRepository_server_stub) archive(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Archive(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) authorize(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+	var a2 string
+	a2 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Authorize(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) finalize(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 []byte
+	a1 = serviceweaver_dec_slice_byte_87461245(dec)
+	var a2 []byte
+	a2 = serviceweaver_dec_slice_byte_87461245(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Finalize(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) getAccess(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.GetAccess(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) getFiles(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.GetFiles(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_string_4af10117(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) getRole(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.GetRole(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.String(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) getState(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.GetState(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) getVisibleFiles(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.GetVisibleFiles(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_string_4af10117(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) listVersions(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.ListVersions(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_DocumentVersion_b96aaf13(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) readFile(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.ReadFile(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) readFinalPDF(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.ReadFinalPDF(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) readVersion(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 int
+	a1 = dec.Int()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.ReadVersion(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) saveVariantForFile(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 []byte
+	a1 = serviceweaver_dec_slice_byte_87461245(dec)
+	var a2 string
+	a2 = dec.String()
+	var a3 string
+	a3 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.SaveVariantForFile(ctx, a0, a1, a2, a3)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) share(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+	var a2 string
+	a2 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Share(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s aDocRepository_server_stub) undo(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Undo(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type authenticator_server_stub struct {
+	impl    Authenticator
+	addLoad func(key uint64, load float64)
+}
+
+// Check that authenticator_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*authenticator_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s authenticator_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Authenticate":
+		return s.authenticate
+	case "IssueSession":
+		return s.issueSession
+	case "OIDCEnabled":
+		return s.oIDCEnabled
+	case "OIDCExchange":
+		return s.oIDCExchange
+	case "OIDCLoginURL":
+		return s.oIDCLoginURL
+	default:
+		return nil
+	}
+}
+
+func (s authenticator_server_stub) authenticate(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 Credentials
+	(&a0).WeaverUnmarshal(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Authenticate(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s authenticator_server_stub) issueSession(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 User
+	(&a0).WeaverUnmarshal(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.IssueSession(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.String(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s authenticator_server_stub) oIDCEnabled(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.OIDCEnabled(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Bool(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s authenticator_server_stub) oIDCExchange(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.OIDCExchange(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s authenticator_server_stub) oIDCLoginURL(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.OIDCLoginURL(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.String(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type chatGPTRepository_server_stub struct {
+	impl    ChatGPTRepository
+	addLoad func(key uint64, load float64)
+}
+
+// Check that chatGPTRepository_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*chatGPTRepository_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s chatGPTRepository_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "ChangeMarkup":
+		return s.changeMarkup
+	case "EstimateUsage":
+		return s.estimateUsage
+	default:
+		return nil
+	}
+}
+
+func (s chatGPTRepository_server_stub) changeMarkup(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+	var a2 bool
+	a2 = dec.Bool()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, r1, appErr := s.impl.ChangeMarkup(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	(r1).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s chatGPTRepository_server_stub) estimateUsage(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.EstimateUsage(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type invoiceService_server_stub struct {
+	impl    InvoiceService
+	addLoad func(key uint64, load float64)
+}
+
+// Check that invoiceService_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*invoiceService_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s invoiceService_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Check":
+		return s.check
+	case "Reconcile":
+		return s.reconcile
+	default:
+		return nil
+	}
+}
+
+func (s invoiceService_server_stub) check(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []byte
+	a0 = serviceweaver_dec_slice_byte_87461245(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Check(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s invoiceService_server_stub) reconcile(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []byte
+	a0 = serviceweaver_dec_slice_byte_87461245(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, r1, appErr := s.impl.Reconcile(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	(r1).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type mailMerger_server_stub struct {
+	impl    MailMerger
+	addLoad func(key uint64, load float64)
+}
+
+// Check that mailMerger_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*mailMerger_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s mailMerger_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "MergeToFolder":
+		return s.mergeToFolder
+	case "MergeToZip":
+		return s.mergeToZip
+	default:
+		return nil
+	}
+}
+
+func (s mailMerger_server_stub) mergeToFolder(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 []MergeRow
+	a1 = serviceweaver_dec_slice_MergeRow_61df193f(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.MergeToFolder(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_string_4af10117(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s mailMerger_server_stub) mergeToZip(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 []MergeRow
+	a1 = serviceweaver_dec_slice_MergeRow_61df193f(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.MergeToZip(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type main_server_stub struct {
+	impl    weaver.Main
+	addLoad func(key uint64, load float64)
+}
+
+// Check that main_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*main_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s main_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	default:
+		return nil
+	}
+}
+
+type pDFGenerator_server_stub struct {
+	impl    PDFGenerator
+	addLoad func(key uint64, load float64)
+}
+
+// Check that pDFGenerator_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*pDFGenerator_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s pDFGenerator_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "GeneratePDF":
+		return s.generatePDF
+	case "GuardMarkup":
+		return s.guardMarkup
+	default:
+		return nil
+	}
+}
+
+func (s pDFGenerator_server_stub) generatePDF(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []byte
+	a0 = serviceweaver_dec_slice_byte_87461245(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.GeneratePDF(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s pDFGenerator_server_stub) guardMarkup(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []byte
+	a0 = serviceweaver_dec_slice_byte_87461245(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, r1, appErr := s.impl.GuardMarkup(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	serviceweaver_enc_slice_BlockedDirective_f4d6bad9(enc, r1)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type promptTemplates_server_stub struct {
+	impl    PromptTemplates
+	addLoad func(key uint64, load float64)
+}
+
+// Check that promptTemplates_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*promptTemplates_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s promptTemplates_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Delete":
+		return s.delete
+	case "Get":
+		return s.get
+	case "List":
+		return s.list
+	case "Save":
+		return s.save
+	default:
+		return nil
+	}
+}
+
+func (s promptTemplates_server_stub) delete(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Delete(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s promptTemplates_server_stub) get(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Get(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s promptTemplates_server_stub) list(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.List(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_PromptTemplate_17961a57(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s promptTemplates_server_stub) save(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 PromptTemplate
+	(&a0).WeaverUnmarshal(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Save(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type sequenceService_server_stub struct {
+	impl    SequenceService
+	addLoad func(key uint64, load float64)
+}
+
+// Check that sequenceService_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*sequenceService_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s sequenceService_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Commit":
+		return s.commit
+	case "FillPlaceholders":
+		return s.fillPlaceholders
+	case "Next":
+		return s.next
+	case "Release":
+		return s.release
+	default:
+		return nil
+	}
+}
+
+func (s sequenceService_server_stub) commit(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Commit(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s sequenceService_server_stub) fillPlaceholders(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 []byte
+	a1 = serviceweaver_dec_slice_byte_87461245(dec)
+	var a2 bool
+	a2 = dec.Bool()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, r1, appErr := s.impl.FillPlaceholders(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_byte_87461245(enc, r0)
+	enc.String(r1)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s sequenceService_server_stub) next(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+	var a2 string
+	a2 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Next(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.String(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s sequenceService_server_stub) release(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Release(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type shareLinks_server_stub struct {
+	impl    ShareLinks
+	addLoad func(key uint64, load float64)
+}
+
+// Check that shareLinks_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*shareLinks_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s shareLinks_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Create":
+		return s.create
+	case "Get":
+		return s.get
+	case "List":
+		return s.list
+	case "Resolve":
+		return s.resolve
+	case "Revoke":
+		return s.revoke
+	default:
+		return nil
+	}
+}
+
+func (s shareLinks_server_stub) create(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 ShareLink
+	(&a0).WeaverUnmarshal(dec)
+	var a1 []byte
+	a1 = serviceweaver_dec_slice_byte_87461245(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, r1, appErr := s.impl.Create(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.String(r1)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s shareLinks_server_stub) get(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Get(ctx, a0)
+
+	// Encode the results.
 	enc := codegen.NewEncoder()
-	enc.Reset(size)
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
 
-	// Encode arguments.
-	serviceweaver_enc_slice_byte_87461245(enc, a0)
-	var shardKey uint64
+func (s shareLinks_server_stub) list(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
 
-	// Call the remote method.
-	requestBytes = len(enc.Data())
-	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
-	}
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.List(ctx)
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = serviceweaver_dec_slice_byte_87461245(dec)
-	err = dec.Error()
-	return
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_ShareLink_d1f3dde3(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
 }
 
-type speechRepository_client_stub struct {
-	stub                codegen.Stub
-	speechToTextMetrics *codegen.MethodMetrics
+func (s shareLinks_server_stub) resolve(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, r1, appErr := s.impl.Resolve(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	serviceweaver_enc_slice_byte_87461245(enc, r1)
+	enc.Error(appErr)
+	return enc.Data(), nil
 }
 
-// Check that speechRepository_client_stub implements the SpeechRepository interface.
-var _ SpeechRepository = (*speechRepository_client_stub)(nil)
+func (s shareLinks_server_stub) revoke(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
 
-func (s speechRepository_client_stub) SpeechToText(ctx context.Context, a0 []byte) (r0 string, err error) {
-	// Update metrics.
-	var requestBytes, replyBytes int
-	begin := s.speechToTextMetrics.Begin()
-	defer func() { s.speechToTextMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
 
-	span := trace.SpanFromContext(ctx)
-	if span.SpanContext().IsValid() {
-		// Create a child span for this method.
-		ctx, span = s.stub.Tracer().Start(ctx, "main.SpeechRepository.SpeechToText", trace.WithSpanKind(trace.SpanKindClient))
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Revoke(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type speechRepository_server_stub struct {
+	impl    SpeechRepository
+	addLoad func(key uint64, load float64)
+}
+
+// Check that speechRepository_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*speechRepository_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s speechRepository_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "SpeechToText":
+		return s.speechToText
+	default:
+		return nil
 	}
+}
 
+func (s speechRepository_server_stub) speechToText(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
-		// Catch and return any panics detected during encoding/decoding/rpc.
 		if err == nil {
 			err = codegen.CatchPanics(recover())
-			if err != nil {
-				err = errors.Join(weaver.RemoteCallError, err)
-			}
 		}
+	}()
 
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
-		span.End()
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []byte
+	a0 = serviceweaver_dec_slice_byte_87461245(dec)
+	var a1 TranscriptionOptions
+	(&a1).WeaverUnmarshal(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.SpeechToText(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.String(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+type textToSpeech_server_stub struct {
+	impl    TextToSpeech
+	addLoad func(key uint64, load float64)
+}
+
+// Check that textToSpeech_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*textToSpeech_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s textToSpeech_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Synthesize":
+		return s.synthesize
+	default:
+		return nil
+	}
+}
 
+func (s textToSpeech_server_stub) synthesize(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
 	}()
 
-	// Preallocate a buffer of the right size.
-	size := 0
-	size += (4 + (len(a0) * 1))
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Synthesize(ctx, a0, a1)
+
+	// Encode the results.
 	enc := codegen.NewEncoder()
-	enc.Reset(size)
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
 
-	// Encode arguments.
-	serviceweaver_enc_slice_byte_87461245(enc, a0)
-	var shardKey uint64
+type usageLedger_server_stub struct {
+	impl    UsageLedger
+	addLoad func(key uint64, load float64)
+}
 
-	// Call the remote method.
-	requestBytes = len(enc.Data())
-	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
-	replyBytes = len(results)
-	if err != nil {
-		err = errors.Join(weaver.RemoteCallError, err)
-		return
+// Check that usageLedger_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*usageLedger_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s usageLedger_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "CheckBudget":
+		return s.checkBudget
+	case "Record":
+		return s.record
+	case "Summarize":
+		return s.summarize
+	default:
+		return nil
 	}
+}
 
-	// Decode the results.
-	dec := codegen.NewDecoder(results)
-	r0 = dec.String()
-	err = dec.Error()
-	return
+func (s usageLedger_server_stub) checkBudget(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+	var a2 TokenUsage
+	(&a2).WeaverUnmarshal(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.CheckBudget(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s usageLedger_server_stub) record(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 UsageRecord
+	(&a0).WeaverUnmarshal(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Record(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s usageLedger_server_stub) summarize(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 UsageQuery
+	(&a0).WeaverUnmarshal(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Summarize(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_UsageAggregate_ae309bd1(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
 }
 
-// Server stub implementations.
-
-type aDocRepository_server_stub struct {
-	impl    ADocRepository
+type voiceArchive_server_stub struct {
+	impl    VoiceArchive
 	addLoad func(key uint64, load float64)
 }
 
-// Check that aDocRepository_server_stub implements the codegen.Server interface.
-var _ codegen.Server = (*aDocRepository_server_stub)(nil)
+// Check that voiceArchive_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*voiceArchive_server_stub)(nil)
 
 // GetStubFn implements the codegen.Server interface.
-func (s aDocRepository_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+func (s voiceArchive_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
 	switch method {
-	case "GetFiles":
-		return s.getFiles
-	case "ReadFile":
-		return s.readFile
-	case "SaveVariantForFile":
-		return s.saveVariantForFile
+	case "List":
+		return s.list
+	case "ReadAudio":
+		return s.readAudio
+	case "Store":
+		return s.store
 	default:
 		return nil
 	}
 }
 
-func (s aDocRepository_server_stub) getFiles(ctx context.Context, args []byte) (res []byte, err error) {
+func (s voiceArchive_server_stub) list(ctx context.Context, args []byte) (res []byte, err error) {
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
@@ -697,19 +5811,24 @@ func (s aDocRepository_server_stub) getFiles(ctx context.Context, args []byte) (
 		}
 	}()
 
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.GetFiles(ctx)
+	r0, appErr := s.impl.List(ctx, a0)
 
 	// Encode the results.
 	enc := codegen.NewEncoder()
-	serviceweaver_enc_slice_string_4af10117(enc, r0)
+	serviceweaver_enc_slice_VoicePrompt_012db793(enc, r0)
 	enc.Error(appErr)
 	return enc.Data(), nil
 }
 
-func (s aDocRepository_server_stub) readFile(ctx context.Context, args []byte) (res []byte, err error) {
+func (s voiceArchive_server_stub) readAudio(ctx context.Context, args []byte) (res []byte, err error) {
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
 		if err == nil {
@@ -725,232 +5844,950 @@ func (s aDocRepository_server_stub) readFile(ctx context.Context, args []byte) (
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
 	// Call the local method.
-	r0, appErr := s.impl.ReadFile(ctx, a0)
+	r0, r1, appErr := s.impl.ReadAudio(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	serviceweaver_enc_slice_byte_87461245(enc, r1)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s voiceArchive_server_stub) store(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 VoicePrompt
+	(&a0).WeaverUnmarshal(dec)
+	var a1 []byte
+	a1 = serviceweaver_dec_slice_byte_87461245(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Store(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.String(r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*AccessList)(nil)
+
+type __is_AccessList[T ~struct {
+	weaver.AutoMarshal
+	Grants       map[string]string "json:\"grants\""
+	FolderGrants map[string]string "json:\"folderGrants\""
+}] struct{}
+
+var _ __is_AccessList[AccessList]
+
+func (x *AccessList) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("AccessList.WeaverMarshal: nil receiver"))
+	}
+	serviceweaver_enc_map_string_string_219dd46d(enc, x.Grants)
+	serviceweaver_enc_map_string_string_219dd46d(enc, x.FolderGrants)
+}
+
+func (x *AccessList) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("AccessList.WeaverUnmarshal: nil receiver"))
+	}
+	x.Grants = serviceweaver_dec_map_string_string_219dd46d(dec)
+	x.FolderGrants = serviceweaver_dec_map_string_string_219dd46d(dec)
+}
+
+func serviceweaver_enc_map_string_string_219dd46d(enc *codegen.Encoder, arg map[string]string) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for k, v := range arg {
+		enc.String(k)
+		enc.String(v)
+	}
+}
+
+func serviceweaver_dec_map_string_string_219dd46d(dec *codegen.Decoder) map[string]string {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make(map[string]string, n)
+	var k string
+	var v string
+	for i := 0; i < n; i++ {
+		k = dec.String()
+		v = dec.String()
+		res[k] = v
+	}
+	return res
+}
+
+var _ codegen.AutoMarshal = (*BlockedDirective)(nil)
+
+type __is_BlockedDirective[T ~struct {
+	weaver.AutoMarshal
+	Line      int    "json:\"line\""
+	Directive string "json:\"directive\""
+	Reason    string "json:\"reason\""
+}] struct{}
+
+var _ __is_BlockedDirective[BlockedDirective]
+
+func (x *BlockedDirective) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("BlockedDirective.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.Line)
+	enc.String(x.Directive)
+	enc.String(x.Reason)
+}
+
+func (x *BlockedDirective) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("BlockedDirective.WeaverUnmarshal: nil receiver"))
+	}
+	x.Line = dec.Int()
+	x.Directive = dec.String()
+	x.Reason = dec.String()
+}
+
+var _ codegen.AutoMarshal = (*Credentials)(nil)
+
+type __is_Credentials[T ~struct {
+	weaver.AutoMarshal
+	APIKey  string
+	Session string
+}] struct{}
+
+var _ __is_Credentials[Credentials]
+
+func (x *Credentials) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Credentials.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.APIKey)
+	enc.String(x.Session)
+}
+
+func (x *Credentials) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Credentials.WeaverUnmarshal: nil receiver"))
+	}
+	x.APIKey = dec.String()
+	x.Session = dec.String()
+}
+
+var _ codegen.AutoMarshal = (*DocumentState)(nil)
+
+type __is_DocumentState[T ~struct {
+	weaver.AutoMarshal
+	Status      string    "json:\"status\""
+	FinalizedAt time.Time "json:\"finalizedAt,omitempty\""
+	PDFHash     string    "json:\"pdfHash,omitempty\""
+}] struct{}
+
+var _ __is_DocumentState[DocumentState]
+
+func (x *DocumentState) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("DocumentState.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Status)
+	enc.EncodeBinaryMarshaler(&x.FinalizedAt)
+	enc.String(x.PDFHash)
+}
+
+func (x *DocumentState) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("DocumentState.WeaverUnmarshal: nil receiver"))
+	}
+	x.Status = dec.String()
+	dec.DecodeBinaryUnmarshaler(&x.FinalizedAt)
+	x.PDFHash = dec.String()
+}
+
+var _ codegen.AutoMarshal = (*DocumentVersion)(nil)
+
+type __is_DocumentVersion[T ~struct {
+	weaver.AutoMarshal
+	Version       int       "json:\"version\""
+	CreatedAt     time.Time "json:\"createdAt\""
+	Author        string    "json:\"author,omitempty\""
+	Original      bool      "json:\"original,omitempty\""
+	VoicePromptID string    "json:\"voicePromptId,omitempty\""
+}] struct{}
+
+var _ __is_DocumentVersion[DocumentVersion]
+
+func (x *DocumentVersion) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("DocumentVersion.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.Version)
+	enc.EncodeBinaryMarshaler(&x.CreatedAt)
+	enc.String(x.Author)
+	enc.Bool(x.Original)
+	enc.String(x.VoicePromptID)
+}
+
+func (x *DocumentVersion) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("DocumentVersion.WeaverUnmarshal: nil receiver"))
+	}
+	x.Version = dec.Int()
+	dec.DecodeBinaryUnmarshaler(&x.CreatedAt)
+	x.Author = dec.String()
+	x.Original = dec.Bool()
+	x.VoicePromptID = dec.String()
+}
+
+var _ codegen.AutoMarshal = (*InvoiceReport)(nil)
+
+type __is_InvoiceReport[T ~struct {
+	weaver.AutoMarshal
+	IsInvoice bool       "json:\"isInvoice\""
+	Items     []LineItem "json:\"items,omitempty\""
+	Subtotal  string     "json:\"subtotal,omitempty\""
+	VAT       string     "json:\"vat,omitempty\""
+	Total     string     "json:\"total,omitempty\""
+	Issues    []string   "json:\"issues,omitempty\""
+}] struct{}
+
+var _ __is_InvoiceReport[InvoiceReport]
+
+func (x *InvoiceReport) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvoiceReport.WeaverMarshal: nil receiver"))
+	}
+	enc.Bool(x.IsInvoice)
+	serviceweaver_enc_slice_LineItem_2cbfefc7(enc, x.Items)
+	enc.String(x.Subtotal)
+	enc.String(x.VAT)
+	enc.String(x.Total)
+	serviceweaver_enc_slice_string_4af10117(enc, x.Issues)
+}
+
+func (x *InvoiceReport) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvoiceReport.WeaverUnmarshal: nil receiver"))
+	}
+	x.IsInvoice = dec.Bool()
+	x.Items = serviceweaver_dec_slice_LineItem_2cbfefc7(dec)
+	x.Subtotal = dec.String()
+	x.VAT = dec.String()
+	x.Total = dec.String()
+	x.Issues = serviceweaver_dec_slice_string_4af10117(dec)
+}
+
+func serviceweaver_enc_slice_LineItem_2cbfefc7(enc *codegen.Encoder, arg []LineItem) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_LineItem_2cbfefc7(dec *codegen.Decoder) []LineItem {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]LineItem, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
+
+func serviceweaver_enc_slice_string_4af10117(enc *codegen.Encoder, arg []string) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.String(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_string_4af10117(dec *codegen.Decoder) []string {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]string, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.String()
+	}
+	return res
+}
+
+var _ codegen.AutoMarshal = (*LineItem)(nil)
+
+type __is_LineItem[T ~struct {
+	weaver.AutoMarshal
+	Position    string "json:\"position\""
+	Description string "json:\"description\""
+	Quantity    string "json:\"quantity,omitempty\""
+	UnitPrice   string "json:\"unitPrice,omitempty\""
+	Amount      string "json:\"amount\""
+}] struct{}
+
+var _ __is_LineItem[LineItem]
+
+func (x *LineItem) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("LineItem.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Position)
+	enc.String(x.Description)
+	enc.String(x.Quantity)
+	enc.String(x.UnitPrice)
+	enc.String(x.Amount)
+}
+
+func (x *LineItem) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("LineItem.WeaverUnmarshal: nil receiver"))
+	}
+	x.Position = dec.String()
+	x.Description = dec.String()
+	x.Quantity = dec.String()
+	x.UnitPrice = dec.String()
+	x.Amount = dec.String()
+}
+
+var _ codegen.AutoMarshal = (*MergeRow)(nil)
+
+type __is_MergeRow[T ~struct {
+	weaver.AutoMarshal
+	Attributes map[string]string
+	Items      [][]string
+}] struct{}
+
+var _ __is_MergeRow[MergeRow]
+
+func (x *MergeRow) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("MergeRow.WeaverMarshal: nil receiver"))
+	}
+	serviceweaver_enc_map_string_string_219dd46d(enc, x.Attributes)
+	serviceweaver_enc_slice_slice_string_bbddd19d(enc, x.Items)
+}
+
+func (x *MergeRow) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("MergeRow.WeaverUnmarshal: nil receiver"))
+	}
+	x.Attributes = serviceweaver_dec_map_string_string_219dd46d(dec)
+	x.Items = serviceweaver_dec_slice_slice_string_bbddd19d(dec)
+}
+
+func serviceweaver_enc_slice_slice_string_bbddd19d(enc *codegen.Encoder, arg [][]string) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		serviceweaver_enc_slice_string_4af10117(enc, arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_slice_string_bbddd19d(dec *codegen.Decoder) [][]string {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([][]string, n)
+	for i := 0; i < n; i++ {
+		res[i] = serviceweaver_dec_slice_string_4af10117(dec)
+	}
+	return res
+}
+
+var _ codegen.AutoMarshal = (*PromptTemplate)(nil)
+
+type __is_PromptTemplate[T ~struct {
+	weaver.AutoMarshal
+	ID           string    "json:\"id\""
+	Name         string    "json:\"name\""
+	Prompt       string    "json:\"prompt\""
+	Placeholders []string  "json:\"placeholders\""
+	UpdatedBy    string    "json:\"updatedBy,omitempty\""
+	UpdatedAt    time.Time "json:\"updatedAt\""
+}] struct{}
+
+var _ __is_PromptTemplate[PromptTemplate]
+
+func (x *PromptTemplate) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("PromptTemplate.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.ID)
+	enc.String(x.Name)
+	enc.String(x.Prompt)
+	serviceweaver_enc_slice_string_4af10117(enc, x.Placeholders)
+	enc.String(x.UpdatedBy)
+	enc.EncodeBinaryMarshaler(&x.UpdatedAt)
+}
+
+func (x *PromptTemplate) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("PromptTemplate.WeaverUnmarshal: nil receiver"))
+	}
+	x.ID = dec.String()
+	x.Name = dec.String()
+	x.Prompt = dec.String()
+	x.Placeholders = serviceweaver_dec_slice_string_4af10117(dec)
+	x.UpdatedBy = dec.String()
+	dec.DecodeBinaryUnmarshaler(&x.UpdatedAt)
+}
+
+var _ codegen.AutoMarshal = (*ShareLink)(nil)
+
+type __is_ShareLink[T ~struct {
+	weaver.AutoMarshal
+	ID        string    "json:\"id\""
+	Document  string    "json:\"document\""
+	Pinned    bool      "json:\"pinned\""
+	Version   int       "json:\"version,omitempty\""
+	CreatedBy string    "json:\"createdBy,omitempty\""
+	CreatedAt time.Time "json:\"createdAt\""
+	ExpiresAt time.Time "json:\"expiresAt\""
+	RevokedAt time.Time "json:\"revokedAt,omitempty\""
+}] struct{}
+
+var _ __is_ShareLink[ShareLink]
+
+func (x *ShareLink) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("ShareLink.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.ID)
+	enc.String(x.Document)
+	enc.Bool(x.Pinned)
+	enc.Int(x.Version)
+	enc.String(x.CreatedBy)
+	enc.EncodeBinaryMarshaler(&x.CreatedAt)
+	enc.EncodeBinaryMarshaler(&x.ExpiresAt)
+	enc.EncodeBinaryMarshaler(&x.RevokedAt)
+}
+
+func (x *ShareLink) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("ShareLink.WeaverUnmarshal: nil receiver"))
+	}
+	x.ID = dec.String()
+	x.Document = dec.String()
+	x.Pinned = dec.Bool()
+	x.Version = dec.Int()
+	x.CreatedBy = dec.String()
+	dec.DecodeBinaryUnmarshaler(&x.CreatedAt)
+	dec.DecodeBinaryUnmarshaler(&x.ExpiresAt)
+	dec.DecodeBinaryUnmarshaler(&x.RevokedAt)
+}
+
+var _ codegen.AutoMarshal = (*SpeechAudio)(nil)
+
+type __is_SpeechAudio[T ~struct {
+	weaver.AutoMarshal
+	Audio       []byte
+	ContentType string
+}] struct{}
+
+var _ __is_SpeechAudio[SpeechAudio]
+
+func (x *SpeechAudio) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("SpeechAudio.WeaverMarshal: nil receiver"))
+	}
+	serviceweaver_enc_slice_byte_87461245(enc, x.Audio)
+	enc.String(x.ContentType)
+}
+
+func (x *SpeechAudio) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("SpeechAudio.WeaverUnmarshal: nil receiver"))
+	}
+	x.Audio = serviceweaver_dec_slice_byte_87461245(dec)
+	x.ContentType = dec.String()
+}
+
+func serviceweaver_enc_slice_byte_87461245(enc *codegen.Encoder, arg []byte) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.Byte(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_byte_87461245(dec *codegen.Decoder) []byte {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]byte, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.Byte()
+	}
+	return res
+}
+
+var _ codegen.AutoMarshal = (*TokenUsage)(nil)
+
+type __is_TokenUsage[T ~struct {
+	weaver.AutoMarshal
+	Model            string "json:\"model\""
+	PromptTokens     int    "json:\"promptTokens\""
+	CompletionTokens int    "json:\"completionTokens\""
+	Cached           bool   "json:\"cached,omitempty\""
+}] struct{}
+
+var _ __is_TokenUsage[TokenUsage]
+
+func (x *TokenUsage) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("TokenUsage.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Model)
+	enc.Int(x.PromptTokens)
+	enc.Int(x.CompletionTokens)
+	enc.Bool(x.Cached)
+}
 
-	// Encode the results.
-	enc := codegen.NewEncoder()
-	serviceweaver_enc_slice_byte_87461245(enc, r0)
-	enc.Error(appErr)
-	return enc.Data(), nil
+func (x *TokenUsage) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("TokenUsage.WeaverUnmarshal: nil receiver"))
+	}
+	x.Model = dec.String()
+	x.PromptTokens = dec.Int()
+	x.CompletionTokens = dec.Int()
+	x.Cached = dec.Bool()
 }
 
-func (s aDocRepository_server_stub) saveVariantForFile(ctx context.Context, args []byte) (res []byte, err error) {
-	// Catch and return any panics detected during encoding/decoding/rpc.
-	defer func() {
-		if err == nil {
-			err = codegen.CatchPanics(recover())
-		}
-	}()
+var _ codegen.AutoMarshal = (*TranscriptionOptions)(nil)
 
-	// Decode arguments.
-	dec := codegen.NewDecoder(args)
-	var a0 string
-	a0 = dec.String()
-	var a1 []byte
-	a1 = serviceweaver_dec_slice_byte_87461245(dec)
+type __is_TranscriptionOptions[T ~struct {
+	weaver.AutoMarshal
+	Language string
+	Prompt   string
+}] struct{}
 
-	// TODO(rgrandl): The deferred function above will recover from panics in the
-	// user code: fix this.
-	// Call the local method.
-	appErr := s.impl.SaveVariantForFile(ctx, a0, a1)
+var _ __is_TranscriptionOptions[TranscriptionOptions]
 
-	// Encode the results.
-	enc := codegen.NewEncoder()
-	enc.Error(appErr)
-	return enc.Data(), nil
+func (x *TranscriptionOptions) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("TranscriptionOptions.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Language)
+	enc.String(x.Prompt)
 }
 
-type chatGPTRepository_server_stub struct {
-	impl    ChatGPTRepository
-	addLoad func(key uint64, load float64)
+func (x *TranscriptionOptions) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("TranscriptionOptions.WeaverUnmarshal: nil receiver"))
+	}
+	x.Language = dec.String()
+	x.Prompt = dec.String()
 }
 
-// Check that chatGPTRepository_server_stub implements the codegen.Server interface.
-var _ codegen.Server = (*chatGPTRepository_server_stub)(nil)
+var _ codegen.AutoMarshal = (*UsageAggregate)(nil)
 
-// GetStubFn implements the codegen.Server interface.
-func (s chatGPTRepository_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
-	switch method {
-	case "ChangeMarkup":
-		return s.changeMarkup
-	default:
-		return nil
+type __is_UsageAggregate[T ~struct {
+	weaver.AutoMarshal
+	Key              string  "json:\"key\""
+	Calls            int     "json:\"calls\""
+	PromptTokens     int     "json:\"promptTokens\""
+	CompletionTokens int     "json:\"completionTokens\""
+	Cost             float64 "json:\"cost\""
+}] struct{}
+
+var _ __is_UsageAggregate[UsageAggregate]
+
+func (x *UsageAggregate) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("UsageAggregate.WeaverMarshal: nil receiver"))
 	}
+	enc.String(x.Key)
+	enc.Int(x.Calls)
+	enc.Int(x.PromptTokens)
+	enc.Int(x.CompletionTokens)
+	enc.Float64(x.Cost)
 }
 
-func (s chatGPTRepository_server_stub) changeMarkup(ctx context.Context, args []byte) (res []byte, err error) {
-	// Catch and return any panics detected during encoding/decoding/rpc.
-	defer func() {
-		if err == nil {
-			err = codegen.CatchPanics(recover())
-		}
-	}()
+func (x *UsageAggregate) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("UsageAggregate.WeaverUnmarshal: nil receiver"))
+	}
+	x.Key = dec.String()
+	x.Calls = dec.Int()
+	x.PromptTokens = dec.Int()
+	x.CompletionTokens = dec.Int()
+	x.Cost = dec.Float64()
+}
 
-	// Decode arguments.
-	dec := codegen.NewDecoder(args)
-	var a0 string
-	a0 = dec.String()
-	var a1 string
-	a1 = dec.String()
+var _ codegen.AutoMarshal = (*UsageQuery)(nil)
 
-	// TODO(rgrandl): The deferred function above will recover from panics in the
-	// user code: fix this.
-	// Call the local method.
-	r0, appErr := s.impl.ChangeMarkup(ctx, a0, a1)
+type __is_UsageQuery[T ~struct {
+	weaver.AutoMarshal
+	GroupBy  string
+	From     time.Time
+	To       time.Time
+	User     string
+	Document string
+}] struct{}
 
-	// Encode the results.
-	enc := codegen.NewEncoder()
-	serviceweaver_enc_slice_byte_87461245(enc, r0)
-	enc.Error(appErr)
-	return enc.Data(), nil
+var _ __is_UsageQuery[UsageQuery]
+
+func (x *UsageQuery) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("UsageQuery.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.GroupBy)
+	enc.EncodeBinaryMarshaler(&x.From)
+	enc.EncodeBinaryMarshaler(&x.To)
+	enc.String(x.User)
+	enc.String(x.Document)
 }
 
-type main_server_stub struct {
-	impl    weaver.Main
-	addLoad func(key uint64, load float64)
+func (x *UsageQuery) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("UsageQuery.WeaverUnmarshal: nil receiver"))
+	}
+	x.GroupBy = dec.String()
+	dec.DecodeBinaryUnmarshaler(&x.From)
+	dec.DecodeBinaryUnmarshaler(&x.To)
+	x.User = dec.String()
+	x.Document = dec.String()
 }
 
-// Check that main_server_stub implements the codegen.Server interface.
-var _ codegen.Server = (*main_server_stub)(nil)
+var _ codegen.AutoMarshal = (*UsageRecord)(nil)
 
-// GetStubFn implements the codegen.Server interface.
-func (s main_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
-	switch method {
-	default:
-		return nil
+type __is_UsageRecord[T ~struct {
+	weaver.AutoMarshal
+	Time     time.Time  "json:\"time\""
+	User     string     "json:\"user,omitempty\""
+	Document string     "json:\"document,omitempty\""
+	Usage    TokenUsage "json:\"usage\""
+	Cost     float64    "json:\"cost\""
+}] struct{}
+
+var _ __is_UsageRecord[UsageRecord]
+
+func (x *UsageRecord) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("UsageRecord.WeaverMarshal: nil receiver"))
 	}
+	enc.EncodeBinaryMarshaler(&x.Time)
+	enc.String(x.User)
+	enc.String(x.Document)
+	(x.Usage).WeaverMarshal(enc)
+	enc.Float64(x.Cost)
 }
 
-type pDFGenerator_server_stub struct {
-	impl    PDFGenerator
-	addLoad func(key uint64, load float64)
+func (x *UsageRecord) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("UsageRecord.WeaverUnmarshal: nil receiver"))
+	}
+	dec.DecodeBinaryUnmarshaler(&x.Time)
+	x.User = dec.String()
+	x.Document = dec.String()
+	(&x.Usage).WeaverUnmarshal(dec)
+	x.Cost = dec.Float64()
 }
 
-// Check that pDFGenerator_server_stub implements the codegen.Server interface.
-var _ codegen.Server = (*pDFGenerator_server_stub)(nil)
+var _ codegen.AutoMarshal = (*User)(nil)
 
-// GetStubFn implements the codegen.Server interface.
-func (s pDFGenerator_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
-	switch method {
-	case "GeneratePDF":
-		return s.generatePDF
-	default:
-		return nil
+type __is_User[T ~struct {
+	weaver.AutoMarshal
+	Name   string "json:\"name\""
+	Method string "json:\"method\""
+}] struct{}
+
+var _ __is_User[User]
+
+func (x *User) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("User.WeaverMarshal: nil receiver"))
 	}
+	enc.String(x.Name)
+	enc.String(x.Method)
 }
 
-func (s pDFGenerator_server_stub) generatePDF(ctx context.Context, args []byte) (res []byte, err error) {
-	// Catch and return any panics detected during encoding/decoding/rpc.
-	defer func() {
-		if err == nil {
-			err = codegen.CatchPanics(recover())
-		}
-	}()
+func (x *User) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("User.WeaverUnmarshal: nil receiver"))
+	}
+	x.Name = dec.String()
+	x.Method = dec.String()
+}
 
-	// Decode arguments.
-	dec := codegen.NewDecoder(args)
-	var a0 []byte
-	a0 = serviceweaver_dec_slice_byte_87461245(dec)
+var _ codegen.AutoMarshal = (*VoicePrompt)(nil)
 
-	// TODO(rgrandl): The deferred function above will recover from panics in the
-	// user code: fix this.
-	// Call the local method.
-	r0, appErr := s.impl.GeneratePDF(ctx, a0)
+type __is_VoicePrompt[T ~struct {
+	weaver.AutoMarshal
+	ID              string    "json:\"id\""
+	Document        string    "json:\"document,omitempty\""
+	Transcript      string    "json:\"transcript\""
+	Language        string    "json:\"language,omitempty\""
+	Format          string    "json:\"format\""
+	DurationSeconds float64   "json:\"durationSeconds\""
+	CreatedAt       time.Time "json:\"createdAt\""
+}] struct{}
 
-	// Encode the results.
-	enc := codegen.NewEncoder()
-	serviceweaver_enc_slice_byte_87461245(enc, r0)
-	enc.Error(appErr)
-	return enc.Data(), nil
+var _ __is_VoicePrompt[VoicePrompt]
+
+func (x *VoicePrompt) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("VoicePrompt.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.ID)
+	enc.String(x.Document)
+	enc.String(x.Transcript)
+	enc.String(x.Language)
+	enc.String(x.Format)
+	enc.Float64(x.DurationSeconds)
+	enc.EncodeBinaryMarshaler(&x.CreatedAt)
 }
 
-type speechRepository_server_stub struct {
-	impl    SpeechRepository
-	addLoad func(key uint64, load float64)
+func (x *VoicePrompt) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("VoicePrompt.WeaverUnmarshal: nil receiver"))
+	}
+	x.ID = dec.String()
+	x.Document = dec.String()
+	x.Transcript = dec.String()
+	x.Language = dec.String()
+	x.Format = dec.String()
+	x.DurationSeconds = dec.Float64()
+	dec.DecodeBinaryUnmarshaler(&x.CreatedAt)
 }
 
-// Check that speechRepository_server_stub implements the codegen.Server interface.
-var _ codegen.Server = (*speechRepository_server_stub)(nil)
+// Encoding/decoding implementations.
 
-// GetStubFn implements the codegen.Server interface.
-func (s speechRepository_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
-	switch method {
-	case "SpeechToText":
-		return s.speechToText
-	default:
+func serviceweaver_enc_slice_DocumentVersion_b96aaf13(enc *codegen.Encoder, arg []DocumentVersion) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_DocumentVersion_b96aaf13(dec *codegen.Decoder) []DocumentVersion {
+	n := dec.Len()
+	if n == -1 {
 		return nil
 	}
+	res := make([]DocumentVersion, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
 }
 
-func (s speechRepository_server_stub) speechToText(ctx context.Context, args []byte) (res []byte, err error) {
-	// Catch and return any panics detected during encoding/decoding/rpc.
-	defer func() {
-		if err == nil {
-			err = codegen.CatchPanics(recover())
-		}
-	}()
+func serviceweaver_enc_slice_MergeRow_61df193f(enc *codegen.Encoder, arg []MergeRow) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
 
-	// Decode arguments.
-	dec := codegen.NewDecoder(args)
-	var a0 []byte
-	a0 = serviceweaver_dec_slice_byte_87461245(dec)
+func serviceweaver_dec_slice_MergeRow_61df193f(dec *codegen.Decoder) []MergeRow {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]MergeRow, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
 
-	// TODO(rgrandl): The deferred function above will recover from panics in the
-	// user code: fix this.
-	// Call the local method.
-	r0, appErr := s.impl.SpeechToText(ctx, a0)
+func serviceweaver_enc_slice_BlockedDirective_f4d6bad9(enc *codegen.Encoder, arg []BlockedDirective) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
 
-	// Encode the results.
-	enc := codegen.NewEncoder()
-	enc.String(r0)
-	enc.Error(appErr)
-	return enc.Data(), nil
+func serviceweaver_dec_slice_BlockedDirective_f4d6bad9(dec *codegen.Decoder) []BlockedDirective {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]BlockedDirective, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
 }
 
-// Encoding/decoding implementations.
+func serviceweaver_enc_slice_PromptTemplate_17961a57(enc *codegen.Encoder, arg []PromptTemplate) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
 
-func serviceweaver_enc_slice_string_4af10117(enc *codegen.Encoder, arg []string) {
+func serviceweaver_dec_slice_PromptTemplate_17961a57(dec *codegen.Decoder) []PromptTemplate {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]PromptTemplate, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
+
+func serviceweaver_enc_slice_ShareLink_d1f3dde3(enc *codegen.Encoder, arg []ShareLink) {
 	if arg == nil {
 		enc.Len(-1)
 		return
 	}
 	enc.Len(len(arg))
 	for i := 0; i < len(arg); i++ {
-		enc.String(arg[i])
+		(arg[i]).WeaverMarshal(enc)
 	}
 }
 
-func serviceweaver_dec_slice_string_4af10117(dec *codegen.Decoder) []string {
+func serviceweaver_dec_slice_ShareLink_d1f3dde3(dec *codegen.Decoder) []ShareLink {
 	n := dec.Len()
 	if n == -1 {
 		return nil
 	}
-	res := make([]string, n)
+	res := make([]ShareLink, n)
 	for i := 0; i < n; i++ {
-		res[i] = dec.String()
+		(&res[i]).WeaverUnmarshal(dec)
 	}
 	return res
 }
 
-func serviceweaver_enc_slice_byte_87461245(enc *codegen.Encoder, arg []byte) {
+func serviceweaver_enc_slice_UsageAggregate_ae309bd1(enc *codegen.Encoder, arg []UsageAggregate) {
 	if arg == nil {
 		enc.Len(-1)
 		return
 	}
 	enc.Len(len(arg))
 	for i := 0; i < len(arg); i++ {
-		enc.Byte(arg[i])
+		(arg[i]).WeaverMarshal(enc)
 	}
 }
 
-func serviceweaver_dec_slice_byte_87461245(dec *codegen.Decoder) []byte {
+func serviceweaver_dec_slice_UsageAggregate_ae309bd1(dec *codegen.Decoder) []UsageAggregate {
 	n := dec.Len()
 	if n == -1 {
 		return nil
 	}
-	res := make([]byte, n)
+	res := make([]UsageAggregate, n)
 	for i := 0; i < n; i++ {
-		res[i] = dec.Byte()
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
+
+func serviceweaver_enc_slice_VoicePrompt_012db793(enc *codegen.Encoder, arg []VoicePrompt) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_VoicePrompt_012db793(dec *codegen.Decoder) []VoicePrompt {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]VoicePrompt, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
 	}
 	return res
 }
+
+// Size implementations.
+
+// serviceweaver_size_Credentials_7245487d returns the size (in bytes) of the serialization
+// of the provided type.
+func serviceweaver_size_Credentials_7245487d(x *Credentials) int {
+	size := 0
+	size += 0
+	size += (4 + len(x.APIKey))
+	size += (4 + len(x.Session))
+	return size
+}
+
+// serviceweaver_size_TokenUsage_fbc88ffd returns the size (in bytes) of the serialization
+// of the provided type.
+func serviceweaver_size_TokenUsage_fbc88ffd(x *TokenUsage) int {
+	size := 0
+	size += 0
+	size += (4 + len(x.Model))
+	size += 8
+	size += 8
+	size += 1
+	return size
+}
+
+// serviceweaver_size_TranscriptionOptions_203fede0 returns the size (in bytes) of the serialization
+// of the provided type.
+func serviceweaver_size_TranscriptionOptions_203fede0(x *TranscriptionOptions) int {
+	size := 0
+	size += 0
+	size += (4 + len(x.Language))
+	size += (4 + len(x.Prompt))
+	return size
+}
+
+// serviceweaver_size_User_add32a16 returns the size (in bytes) of the serialization
+// of the provided type.
+func serviceweaver_size_User_add32a16(x *User) int {
+	size := 0
+	size += 0
+	size += (4 + len(x.Name))
+	size += (4 + len(x.Method))
+	return size
+}